@@ -0,0 +1,83 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// annotation is information attached to an error by functions like WithFields or WithCode, which does not
+// change the error message.
+type annotation interface {
+	// kind identifies the annotation in the wire representation of the error (see Encode).
+	kind() string
+	// chainLine returns the line printed for the annotation in the "%+v" error chain, or empty string if
+	// the annotation is printed elsewhere.
+	chainLine() string
+	// setJSON sets the annotation in the JSON representation of the error.
+	setJSON(j *jsonError)
+	// setEncoded sets the annotation in the wire representation of the error.
+	setEncoded(e *encodedError)
+}
+
+// annotationDecoders decode annotations from the wire representation of the error, by their kind.
+var annotationDecoders = map[string]func(e *encodedError) annotation{
+	// Encoded errors can come from anywhere, so odd number of fields is padded the same way WithFields does.
+	kindFields:   func(e *encodedError) annotation { return newFieldsAnnotation(e.Fields) },
+	kindCode:     func(e *encodedError) annotation { return codeAnnotation(e.Code) },
+	kindExitCode: func(e *encodedError) annotation { return exitCodeAnnotation(e.ExitCode) },
+	kindHint:     func(e *encodedError) annotation { return hintAnnotation(e.Message) },
+	kindDetail:   func(e *encodedError) annotation { return detailAnnotation(e.Message) },
+}
+
+// annotated is an error with an annotation. It does not change the error message.
+type annotated struct {
+	err        error
+	annotation annotation
+}
+
+// annotate returns cause annotated with a. If cause is nil, it returns nil, similar to Wrap.
+func annotate(cause error, a annotation) error {
+	if cause == nil {
+		return nil
+	}
+	return &annotated{err: cause, annotation: a}
+}
+
+// Error implements the error interface.
+func (a *annotated) Error() string {
+	return a.err.Error()
+}
+
+// Unwrap implements the error Unwrap interface.
+func (a *annotated) Unwrap() error {
+	return a.err
+}
+
+// Format implements the fmt.Formatter interface to support the formatting of an error chain with "%+v" verb.
+func (a *annotated) Format(s fmt.State, verb rune) {
+	formatError(a, s, verb)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The error is marshaled as a JSON object with
+// the annotation (e.g. "fields" or "code") and the wrapped error nested as "cause".
+func (a *annotated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONError(a))
+}
+
+// annotations returns annotations of type T along the error chain (including all branches of errors
+// wrapping multiple errors), from the outermost error.
+func annotations[T annotation](err error) []T {
+	var as []T
+	Walk(err, func(err error, _ int) bool {
+		if a, ok := err.(*annotated); ok {
+			if t, ok := a.annotation.(T); ok {
+				as = append(as, t)
+			}
+		}
+		return true
+	})
+	return as
+}
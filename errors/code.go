@@ -0,0 +1,156 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// ErrorCode is a canonical error classification code. The values are the same as gRPC status codes,
+// so ErrorCode can be converted directly to google.golang.org/grpc/codes.Code.
+type ErrorCode uint32
+
+const (
+	// OK means no error. Code returns it only for nil error.
+	OK ErrorCode = 0
+	// Canceled means the operation was canceled, typically by the caller.
+	Canceled ErrorCode = 1
+	// Unknown is used for errors without a code.
+	Unknown ErrorCode = 2
+	// InvalidArgument means the client specified an invalid argument.
+	InvalidArgument ErrorCode = 3
+	// DeadlineExceeded means the deadline expired before the operation could complete.
+	DeadlineExceeded ErrorCode = 4
+	// NotFound means some requested entity was not found.
+	NotFound ErrorCode = 5
+	// AlreadyExists means an entity that client attempted to create already exists.
+	AlreadyExists ErrorCode = 6
+	// PermissionDenied means the caller does not have permission to execute the operation.
+	PermissionDenied ErrorCode = 7
+	// ResourceExhausted means some resource (e.g. quota or disk space) has been exhausted.
+	ResourceExhausted ErrorCode = 8
+	// FailedPrecondition means the system is not in a state required for the operation.
+	FailedPrecondition ErrorCode = 9
+	// Aborted means the operation was aborted, typically due to a concurrency issue.
+	Aborted ErrorCode = 10
+	// OutOfRange means the operation was attempted past the valid range.
+	OutOfRange ErrorCode = 11
+	// Unimplemented means the operation is not implemented or not supported.
+	Unimplemented ErrorCode = 12
+	// Internal means some invariant expected by the system has been broken.
+	Internal ErrorCode = 13
+	// Unavailable means the service is currently unavailable, and the operation can be retried.
+	Unavailable ErrorCode = 14
+	// DataLoss means unrecoverable data loss or corruption.
+	DataLoss ErrorCode = 15
+	// Unauthenticated means the request does not have valid authentication credentials.
+	Unauthenticated ErrorCode = 16
+)
+
+var codeNames = [...]string{
+	OK:                 "OK",
+	Canceled:           "Canceled",
+	Unknown:            "Unknown",
+	InvalidArgument:    "InvalidArgument",
+	DeadlineExceeded:   "DeadlineExceeded",
+	NotFound:           "NotFound",
+	AlreadyExists:      "AlreadyExists",
+	PermissionDenied:   "PermissionDenied",
+	ResourceExhausted:  "ResourceExhausted",
+	FailedPrecondition: "FailedPrecondition",
+	Aborted:            "Aborted",
+	OutOfRange:         "OutOfRange",
+	Unimplemented:      "Unimplemented",
+	Internal:           "Internal",
+	Unavailable:        "Unavailable",
+	DataLoss:           "DataLoss",
+	Unauthenticated:    "Unauthenticated",
+}
+
+// String implements the fmt.Stringer interface.
+func (c ErrorCode) String() string {
+	if int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return "ErrorCode(" + strconv.FormatUint(uint64(c), 10) + ")"
+}
+
+// HTTPStatus returns the HTTP status code corresponding to the code, following the gRPC to HTTP mapping.
+func (c ErrorCode) HTTPStatus() int {
+	switch c {
+	case OK:
+		return http.StatusOK
+	case Canceled:
+		// Non-standard "Client Closed Request" status, commonly used for this case.
+		return 499
+	case InvalidArgument, FailedPrecondition, OutOfRange:
+		return http.StatusBadRequest
+	case DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case NotFound:
+		return http.StatusNotFound
+	case AlreadyExists, Aborted:
+		return http.StatusConflict
+	case PermissionDenied:
+		return http.StatusForbidden
+	case ResourceExhausted:
+		return http.StatusTooManyRequests
+	case Unimplemented:
+		return http.StatusNotImplemented
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode returns the numeric gRPC status code corresponding to the code. Convert it with codes.Code(c.GRPCCode()).
+func (c ErrorCode) GRPCCode() uint32 {
+	if int(c) < len(codeNames) {
+		return uint32(c)
+	}
+	return uint32(Unknown)
+}
+
+// WithCode returns a new error, which wraps another error with classification code without changing its message.
+// The code can be retrieved with the Code function, e.g. to choose HTTP or gRPC response status.
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithCode(cause error, code ErrorCode) error {
+	return annotate(cause, codeAnnotation(code))
+}
+
+// Code returns the outermost code attached with WithCode in the error chain. If there is none, it returns
+// Canceled or DeadlineExceeded for context errors and Unknown for other errors. It returns OK for nil error.
+func Code(err error) ErrorCode {
+	if err == nil {
+		return OK
+	}
+	if codes := annotations[codeAnnotation](err); len(codes) > 0 {
+		return ErrorCode(codes[0])
+	}
+
+	switch {
+	case Is(err, context.Canceled):
+		return Canceled
+	case Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	}
+	return Unknown
+}
+
+// codeAnnotation holds code attached with WithCode.
+type codeAnnotation ErrorCode
+
+func (codeAnnotation) kind() string { return kindCode }
+
+func (c codeAnnotation) chainLine() string { return "code: " + ErrorCode(c).String() }
+
+func (c codeAnnotation) setJSON(j *jsonError) { j.Code = ErrorCode(c).String() }
+
+func (c codeAnnotation) setEncoded(e *encodedError) { e.Code = ErrorCode(c) }
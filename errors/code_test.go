@@ -0,0 +1,71 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func ExampleCode() {
+	errNotFound := errors.New("not found")
+	err := errors.Wrap(errors.WithCode(errNotFound, errors.NotFound), "getting block")
+
+	fmt.Println(err, errors.Code(err), errors.Code(err).HTTPStatus(), errors.Code(err).GRPCCode())
+
+	// Output: getting block: not found NotFound 404 5
+}
+
+func TestCode(t *testing.T) {
+	testutil.Ok(t, errors.WithCode(nil, errors.NotFound))
+
+	for _, tc := range []struct {
+		err          error
+		expectedCode errors.ErrorCode
+	}{
+		{err: nil, expectedCode: errors.OK},
+		{err: errors.New(msg), expectedCode: errors.Unknown},
+		{err: errors.WithCode(errors.New(msg), errors.InvalidArgument), expectedCode: errors.InvalidArgument},
+		{
+			// Outermost code wins.
+			err:          errors.WithCode(errors.Wrap(errors.WithCode(errors.New(msg), errors.NotFound), wrapper), errors.Unavailable),
+			expectedCode: errors.Unavailable,
+		},
+		{
+			err:          errors.Wrap(merrors.New(errors.New(msg), errors.WithCode(errors.New(msg), errors.ResourceExhausted)).Err(), wrapper),
+			expectedCode: errors.ResourceExhausted,
+		},
+		{err: errors.Wrap(context.Canceled, wrapper), expectedCode: errors.Canceled},
+		{err: errors.Wrap(context.DeadlineExceeded, wrapper), expectedCode: errors.DeadlineExceeded},
+	} {
+		t.Run("", func(t *testing.T) {
+			testutil.Equals(t, tc.expectedCode, errors.Code(tc.err))
+		})
+	}
+
+	err := errors.WithCode(errors.New(msg), errors.NotFound)
+	testutil.Equals(t, msg, err.Error())
+	testutil.Assert(t, strings.Contains(fmt.Sprintf("%+v", err), "code: NotFound\ntest_error_message\n"))
+}
+
+func TestErrorCode_Mapping(t *testing.T) {
+	testutil.Equals(t, http.StatusOK, errors.OK.HTTPStatus())
+	testutil.Equals(t, http.StatusNotFound, errors.NotFound.HTTPStatus())
+	testutil.Equals(t, http.StatusBadRequest, errors.InvalidArgument.HTTPStatus())
+	testutil.Equals(t, http.StatusServiceUnavailable, errors.Unavailable.HTTPStatus())
+	testutil.Equals(t, http.StatusTooManyRequests, errors.ResourceExhausted.HTTPStatus())
+	testutil.Equals(t, http.StatusInternalServerError, errors.Unknown.HTTPStatus())
+	testutil.Equals(t, http.StatusInternalServerError, errors.ErrorCode(100).HTTPStatus())
+
+	testutil.Equals(t, uint32(14), errors.Unavailable.GRPCCode())
+	testutil.Equals(t, uint32(2), errors.ErrorCode(100).GRPCCode())
+	testutil.Equals(t, "ErrorCode(100)", errors.ErrorCode(100).String())
+}
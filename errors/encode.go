@@ -0,0 +1,317 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// registry holds errors registered with Register and RegisterType.
+var registry = struct {
+	mtx sync.RWMutex
+
+	sentinels     map[string]error
+	sentinelNames map[error]string
+	types         map[string]reflect.Type
+	typeNames     map[reflect.Type]string
+}{
+	sentinels:     map[string]error{},
+	sentinelNames: map[error]string{},
+	types:         map[string]reflect.Type{},
+	typeNames:     map[reflect.Type]string{},
+}
+
+// Register registers sentinel error (e.g. storage.ErrNotFound) under a stable name, so Decode returns the same
+// error value for it and errors.Is keeps working after the error was passed through Encode and Decode.
+// Use the same names in all processes exchanging errors. It panics if name is already registered
+// or sentinel is not comparable.
+func Register(name string, sentinel error) {
+	if sentinel == nil || !reflect.TypeOf(sentinel).Comparable() {
+		panic(fmt.Sprintf("errors: sentinel error registered as %q has to be comparable", name))
+	}
+
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+
+	if _, ok := registry.sentinels[name]; ok {
+		panic(fmt.Sprintf("errors: error name %q already registered", name))
+	}
+	if _, ok := registry.types[name]; ok {
+		panic(fmt.Sprintf("errors: error name %q already registered", name))
+	}
+	registry.sentinels[name] = sentinel
+	registry.sentinelNames[sentinel] = name
+}
+
+// RegisterType registers the type of the given error (e.g. &KeyError{}) under a stable name, so Decode
+// returns error of the same type and errors.As keeps working after the error was passed through Encode
+// and Decode. Errors of the registered type are encoded with encoding/json, so the type has to support JSON
+// marshaling and unmarshaling of all the information it needs. It panics if name is already registered
+// or prototype is nil.
+func RegisterType(name string, prototype error) {
+	if prototype == nil {
+		panic(fmt.Sprintf("errors: error type registered as %q has to be not nil", name))
+	}
+
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+
+	if _, ok := registry.sentinels[name]; ok {
+		panic(fmt.Sprintf("errors: error name %q already registered", name))
+	}
+	if _, ok := registry.types[name]; ok {
+		panic(fmt.Sprintf("errors: error name %q already registered", name))
+	}
+	t := reflect.TypeOf(prototype)
+	registry.types[name] = t
+	registry.typeNames[t] = name
+}
+
+const (
+	kindBase     = "base"
+	kindFields   = "fields"
+	kindCode     = "code"
+	kindExitCode = "exitcode"
+	kindHint     = "hint"
+	kindDetail   = "detail"
+	kindSentinel = "sentinel"
+	kindConstant = "constant"
+	kindTyped    = "typed"
+	kindOpaque   = "opaque"
+)
+
+// encodedError is the wire representation of a single error in the chain.
+type encodedError struct {
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Format    string          `json:"format,omitempty"`
+	StackOnly bool            `json:"stackOnly,omitempty"`
+	Stack     []Frame         `json:"stack,omitempty"`
+	Fields    []interface{}   `json:"fields,omitempty"`
+	Code      ErrorCode       `json:"code,omitempty"`
+	ExitCode  int             `json:"exitCode,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	// Cause is the error wrapped by base error, or the only error wrapped by other errors.
+	Cause *encodedError `json:"cause,omitempty"`
+	// Wrapped contains errors passed with the %w verb for base error, or all wrapped errors for other errors
+	// wrapping multiple errors.
+	Wrapped []*encodedError `json:"wrapped,omitempty"`
+}
+
+// registeredName returns the name err was registered with by Register. The caller has to hold registry.mtx.
+func registeredName(err error) (name string, ok bool) {
+	if !reflect.TypeOf(err).Comparable() {
+		return "", false
+	}
+	defer func() {
+		// Comparable types (e.g. structs with interface fields) can still hold values, which cannot be hashed.
+		if recover() != nil {
+			name, ok = "", false
+		}
+	}()
+	name, ok = registry.sentinelNames[err]
+	return name, ok
+}
+
+// Encode encodes the error chain, so it can be passed to a different process (e.g. in RPC response) and decoded with
+// Decode. Messages, stack traces, fields, codes, hints and details are preserved, as well as the identity of Sentinel
+// errors and errors registered with Register and RegisterType. Other errors are encoded with their messages only.
+// It returns nil for nil error.
+func Encode(err error) []byte {
+	if err == nil {
+		return nil
+	}
+
+	registry.mtx.RLock()
+	e := encode(err)
+	registry.mtx.RUnlock()
+
+	b, merr := json.Marshal(e)
+	if merr != nil {
+		// Some field value cannot be marshaled, fallback to the message only.
+		b, _ = json.Marshal(&encodedError{Kind: kindOpaque, Message: err.Error()})
+	}
+	return b
+}
+
+func encode(err error) *encodedError {
+	if name, ok := registeredName(err); ok {
+		return &encodedError{Kind: kindSentinel, Name: name, Message: err.Error()}
+	}
+	if name, ok := registry.typeNames[reflect.TypeOf(err)]; ok {
+		if payload, merr := json.Marshal(err); merr == nil {
+			return &encodedError{Kind: kindTyped, Name: name, Message: err.Error(), Payload: payload}
+		}
+	}
+
+	var e *encodedError
+	switch t := err.(type) {
+	case Sentinel:
+		return &encodedError{Kind: kindConstant, Message: string(t)}
+	case *base:
+		e = &encodedError{Kind: kindBase, Message: t.info, Format: t.format, StackOnly: t.stackOnly, Stack: t.Frames()}
+		if t.err != nil {
+			e.Cause = encode(t.err)
+		}
+		for _, w := range t.wrapped {
+			e.Wrapped = append(e.Wrapped, encode(w))
+		}
+		return e
+	case *annotated:
+		e = &encodedError{Kind: t.annotation.kind()}
+		t.annotation.setEncoded(e)
+	default:
+		e = &encodedError{Kind: kindOpaque, Message: err.Error()}
+	}
+
+	cs := causes(err)
+	if len(cs) == 1 {
+		e.Cause = encode(cs[0])
+		return e
+	}
+	for _, c := range cs {
+		e.Wrapped = append(e.Wrapped, encode(c))
+	}
+	return e
+}
+
+// Decode decodes the error chain encoded with Encode. Errors created by this package are decoded with their stack
+// traces marked as remote. Errors registered with Register and RegisterType (in this process) are decoded to the
+// registered sentinel value or type. Other errors are decoded to opaque errors with the same message, wrapping decoded
+// errors they wrapped. It returns nil for empty input and an error describing the problem for malformed input.
+func Decode(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+
+	var e encodedError
+	if err := json.Unmarshal(b, &e); err != nil {
+		return Wrapf(err, "decode error %q", b)
+	}
+
+	registry.mtx.RLock()
+	defer registry.mtx.RUnlock()
+	return decode(&e)
+}
+
+func decode(e *encodedError) error {
+	if e == nil {
+		return nil
+	}
+
+	switch e.Kind {
+	case kindSentinel:
+		if s, ok := registry.sentinels[e.Name]; ok {
+			return s
+		}
+	case kindTyped:
+		if t, ok := registry.types[e.Name]; ok {
+			if err, ok := decodeTyped(t, e.Payload); ok {
+				return err
+			}
+		}
+	case kindConstant:
+		return Sentinel(e.Message)
+	case kindBase:
+		cause := decode(e.Cause)
+		if e.StackOnly && cause == nil {
+			// Errors created with WithStack always wrap an error, so the input is malformed.
+			break
+		}
+		b := &base{info: e.Message, format: e.Format, stackOnly: e.StackOnly, err: cause, remote: e.Stack}
+		for _, w := range e.Wrapped {
+			b.wrapped = append(b.wrapped, decode(w))
+		}
+		return b
+	default:
+		if dec, ok := annotationDecoders[e.Kind]; ok {
+			if cause := decode(e.Cause); cause != nil {
+				return &annotated{err: cause, annotation: dec(e)}
+			}
+		}
+	}
+
+	if len(e.Wrapped) > 0 {
+		o := &opaqueMulti{msg: e.Message}
+		for _, w := range e.Wrapped {
+			o.errs = append(o.errs, decode(w))
+		}
+		return o
+	}
+	return &opaque{msg: e.Message, err: decode(e.Cause)}
+}
+
+func decodeTyped(t reflect.Type, payload json.RawMessage) (error, bool) {
+	if t.Kind() == reflect.Ptr {
+		v := reflect.New(t.Elem())
+		if err := json.Unmarshal(payload, v.Interface()); err != nil {
+			return nil, false
+		}
+		err, ok := v.Interface().(error)
+		return err, ok
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(payload, v.Interface()); err != nil {
+		return nil, false
+	}
+	err, ok := v.Elem().Interface().(error)
+	return err, ok
+}
+
+// opaque is an error decoded by Decode, which type is not known in this process.
+type opaque struct {
+	msg string
+	err error
+}
+
+// Error implements the error interface.
+func (o *opaque) Error() string {
+	return o.msg
+}
+
+// Unwrap implements the error Unwrap interface.
+func (o *opaque) Unwrap() error {
+	return o.err
+}
+
+// Format implements the fmt.Formatter interface to support the formatting of an error chain with "%+v" verb.
+func (o *opaque) Format(s fmt.State, verb rune) {
+	formatError(o, s, verb)
+}
+
+// opaqueMulti is an error decoded by Decode, which type is not known in this process, wrapping multiple errors.
+type opaqueMulti struct {
+	msg  string
+	errs []error
+}
+
+// Error implements the error interface.
+func (o *opaqueMulti) Error() string {
+	return o.msg
+}
+
+// Unwrap implements the multiple errors Unwrap interface.
+func (o *opaqueMulti) Unwrap() []error {
+	return o.errs
+}
+
+// Is reports whether any of the wrapped errors matches target, so errors.Is works also before Go 1.20.
+func (o *opaqueMulti) Is(target error) bool {
+	return isAny(o.errs, target)
+}
+
+// As finds the first of the wrapped errors that matches target, so errors.As works also before Go 1.20.
+func (o *opaqueMulti) As(target interface{}) bool {
+	return asAny(o.errs, target)
+}
+
+// Format implements the fmt.Formatter interface to support the formatting of an error chain with "%+v" verb.
+func (o *opaqueMulti) Format(s fmt.State, verb rune) {
+	formatError(o, s, verb)
+}
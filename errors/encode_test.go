@@ -0,0 +1,121 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/testutil"
+)
+
+var errEncodeNotFound = errors.New("not found")
+
+type keyError struct {
+	Key string `json:"key"`
+}
+
+func (k *keyError) Error() string { return "bad key " + k.Key }
+
+func init() {
+	errors.Register("errors_test.NotFound", errEncodeNotFound)
+	errors.Register("io.EOF", io.EOF)
+	errors.RegisterType("errors_test.KeyError", &keyError{})
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testutil.Equals(t, []byte(nil), errors.Encode(nil))
+	testutil.Ok(t, errors.Decode(nil))
+
+	t.Run("registered errors keep identity", func(t *testing.T) {
+		err := errors.Wrap(errors.WithCode(errors.WithFields(errEncodeNotFound, "tenant", "a"), errors.NotFound), wrapper)
+
+		decoded := errors.Decode(errors.Encode(err))
+		testutil.Equals(t, err.Error(), decoded.Error())
+		testutil.Assert(t, errors.Is(decoded, errEncodeNotFound))
+		testutil.Equals(t, errors.NotFound, errors.Code(decoded))
+		testutil.Equals(t, []interface{}{"tenant", "a"}, errors.Fields(decoded))
+
+		// Stack is preserved and marked as remote.
+		testutil.Equals(t, errors.StackTrace(err), errors.StackTrace(decoded))
+		out := fmt.Sprintf("%+v", decoded)
+		testutil.Assert(t, strings.HasPrefix(out, wrapper+"\n> github.com/efficientgo/core/errors_test.TestEncodeDecode.func1\t"), out)
+		testutil.Assert(t, strings.Contains(out, " (remote)\n"), out)
+
+		typed := errors.Decode(errors.Encode(errors.Wrap(&keyError{Key: "k"}, wrapper)))
+		var target *keyError
+		testutil.Assert(t, errors.As(typed, &target))
+		testutil.Equals(t, "k", target.Key)
+	})
+	t.Run("unknown errors are opaque", func(t *testing.T) {
+		err := errors.Wrapf(
+			merrors.New(fmt.Errorf("std: %w", io.EOF), errors.New("core")).Err(),
+			"%s with %w", wrapper, errEncodeNotFound,
+		)
+
+		decoded := errors.Decode(errors.Encode(err))
+		testutil.Equals(t, err.Error(), decoded.Error())
+		testutil.Assert(t, errors.Is(decoded, io.EOF))
+		testutil.Assert(t, errors.Is(decoded, errEncodeNotFound))
+		testutil.Equals(t, errors.Encode(err), errors.Encode(decoded))
+	})
+	t.Run("malformed input", func(t *testing.T) {
+		testutil.Assert(t, strings.HasPrefix(errors.Decode([]byte("{")).Error(), "decode error \"{\""))
+
+		// Odd number of fields is padded, so the error can be printed and marshaled.
+		decoded := errors.Decode([]byte(`{"kind":"fields","fields":["a"],"cause":{"kind":"opaque","message":"x"}}`))
+		testutil.Equals(t, "x", decoded.Error())
+		testutil.Equals(t, []interface{}{"a", "(MISSING)"}, errors.Fields(decoded))
+		testutil.Assert(t, strings.Contains(fmt.Sprintf("%+v", decoded), "a=(MISSING)"), fmt.Sprintf("%+v", decoded))
+		_, err := json.Marshal(decoded)
+		testutil.Ok(t, err)
+
+		// Stack only error without cause is decoded as opaque error.
+		decoded = errors.Decode([]byte(`{"kind":"base","message":"x","stackOnly":true}`))
+		testutil.Equals(t, "x", decoded.Error())
+		testutil.Equals(t, "x\n", fmt.Sprintf("%+v", decoded))
+	})
+}
+
+// valueError is comparable type, which can hold values that cannot be hashed.
+type valueError struct {
+	err error
+}
+
+func (e valueError) Error() string { return "value: " + e.err.Error() }
+
+func TestUnhashableErrors(t *testing.T) {
+	newErr := func() error {
+		return errors.Wrap(valueError{err: merrors.New(io.EOF, io.ErrUnexpectedEOF).Err()}, wrapper)
+	}
+	err := newErr()
+
+	decoded := errors.Decode(errors.Encode(err))
+	testutil.Equals(t, err.Error(), decoded.Error())
+	testutil.Equals(t, errors.Fingerprint(newErr()), errors.Fingerprint(err))
+	testutil.Equals(t, wrapper+": "+errors.RedactedMarker, errors.Redacted(err))
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	for _, register := range []func(){
+		func() { errors.Register("errors_test.Nil", nil) },
+		func() { errors.RegisterType("errors_test.Nil", nil) },
+		func() { errors.Register("io.EOF", io.ErrUnexpectedEOF) },
+		func() { errors.RegisterType("io.EOF", &keyError{}) },
+	} {
+		err := func() (err error) {
+			defer errors.Recover(&err)
+			register()
+			return nil
+		}()
+		testutil.NotOk(t, err)
+	}
+	// Nothing was registered.
+	testutil.Equals(t, "decode: x", errors.Decode([]byte(`{"kind":"typed","name":"errors_test.Nil","message":"decode: x"}`)).Error())
+}
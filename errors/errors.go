@@ -11,6 +11,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -22,54 +23,124 @@ type base struct {
 	stack stacktrace
 	// err is the actual error which is being wrapped with a stacktrace and message information.
 	err error
+	// wrapped contains errors passed with the %w verb to Newf or Wrapf. Their messages are already part of info.
+	wrapped []error
+	// format and args are the arguments passed to Newf or Wrapf, used to print the message without sensitive data.
+	// Only args needed for that are kept (see redactedArgs).
+	format string
+	args   []interface{}
+	// remote contains call frames of the error decoded with Decode, recorded in a different process.
+	remote []Frame
+	// stackOnly is true for errors created with WithStack, which do not add anything to the message.
+	stackOnly bool
 }
 
 // Error implements the error interface.
 func (b *base) Error() string {
+	if b.stackOnly {
+		return b.err.Error()
+	}
 	if b.err != nil {
 		return fmt.Sprintf("%s: %s", b.info, b.err.Error())
 	}
 	return b.info
 }
 
-// Unwrap implements the error Unwrap interface.
+// Unwrap implements the error Unwrap interface. It returns the wrapped cause, or the first error passed with
+// the %w verb to Newf. Other errors passed with the %w verb are matched by Is and As methods, so errors.Is and
+// errors.As find them too, and are visited by Walk.
 func (b *base) Unwrap() error {
+	if b.err == nil && len(b.wrapped) > 0 {
+		return b.wrapped[0]
+	}
 	return b.err
 }
 
+// Is reports whether any error passed with the %w verb matches target. It is used by errors.Is.
+func (b *base) Is(target error) bool {
+	return isAny(b.wrapped, target)
+}
+
+// As finds the first error passed with the %w verb that matches target. It is used by errors.As.
+func (b *base) As(target interface{}) bool {
+	return asAny(b.wrapped, target)
+}
+
+// isAny reports whether any of errs matches target.
+func isAny(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// asAny finds the first of errs that matches target.
+func asAny(errs []error, target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Frames returns the call frames recorded when this error was created, starting from the most recent call.
+func (b *base) Frames() []Frame {
+	if b.remote != nil {
+		return b.remote
+	}
+	return b.stack.frames()
+}
+
 // Format implements the fmt.Formatter interface to support the formatting of an error chain with "%+v" verb.
 // Whenever error is printed with %+v format verb, stacktrace info gets dumped to the output.
 func (b *base) Format(s fmt.State, verb rune) {
+	formatError(b, s, verb)
+}
+
+// formatError formats error using "%+v" verb as an error chain with stacktraces. Other verbs print the error message.
+func formatError(err error, s fmt.State, verb rune) {
 	if verb == 'v' && s.Flag('+') {
-		s.Write([]byte(formatErrorChain(b)))
+		s.Write([]byte(formatErrorChain(err)))
 		return
 	}
 
-	s.Write([]byte(b.Error()))
+	s.Write([]byte(err.Error()))
 }
 
 // New returns a new error with a stacktrace of recent call frames. Each call to New
 // returns a distinct error value even if the text is identical. An alternative of
 // the errors.New function from github.com/pkg/errors.
 func New(message string) error {
-	return &base{
+	b := &base{
 		info:  message,
 		stack: newStackTrace(),
 		err:   nil,
 	}
+	runHooks(b)
+	return b
 }
 
 // Newf is like New, but it formats input according to a format specifier.
-// An alternative of the fmt.Errorf function.
+// An alternative of the fmt.Errorf function. Same as in fmt.Errorf, errors passed with the %w verb
+// (Go 1.20+ for more than one) are wrapped, so errors.Is and errors.As can match them.
 //
 // If no args have been passed, it is same as `New` function without formatting. Character like
 // '%' still has to be escaped in that scenario.
 func Newf(format string, args ...interface{}) error {
-	return &base{
-		info:  fmt.Sprintf(format, args...),
-		stack: newStackTrace(),
-		err:   nil,
+	info, wrapped := sprintf(format, args...)
+	b := &base{
+		info:    info,
+		stack:   newStackTrace(),
+		err:     nil,
+		wrapped: wrapped,
+		format:  format,
+		args:    redactedArgs(args),
 	}
+	runHooks(b)
+	return b
 }
 
 // Wrap returns a new error, which wraps another error with a stacktrace containing recent call frames.
@@ -82,14 +153,17 @@ func Wrap(cause error, message string) error {
 		return nil
 	}
 
-	return &base{
+	b := &base{
 		info:  message,
 		stack: newStackTrace(),
 		err:   cause,
 	}
+	runHooks(b)
+	return b
 }
 
 // Wrapf is like Wrap but the message is formatted with the supplied format specifier.
+// Errors passed with the %w verb are wrapped too, same as in Newf.
 //
 // If no args have been passed, it is same as `Wrap` function without formatting.
 // Character like '%' still has to be escaped in that scenario.
@@ -97,11 +171,91 @@ func Wrapf(cause error, format string, args ...interface{}) error {
 	if cause == nil {
 		return nil
 	}
-	return &base{
-		info:  fmt.Sprintf(format, args...),
-		stack: newStackTrace(),
-		err:   cause,
+	info, wrapped := sprintf(format, args...)
+	b := &base{
+		info:    info,
+		stack:   newStackTrace(),
+		err:     cause,
+		wrapped: wrapped,
+		format:  format,
+		args:    redactedArgs(args),
+	}
+	runHooks(b)
+	return b
+}
+
+// sprintf formats according to a format specifier. If format contains the %w verb, it is formatted
+// by fmt.Errorf and the errors passed with %w are returned.
+func sprintf(format string, args ...interface{}) (string, []error) {
+	args = unmarkArgs(args)
+	if !hasWrapVerb(format) {
+		return fmt.Sprintf(format, args...), nil
+	}
+	err := fmt.Errorf(format, args...)
+	return err.Error(), causes(err)
+}
+
+// hasWrapVerb returns true if format contains the %w verb (with optional flags, width, precision or argument index).
+func hasWrapVerb(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0; i++ {
+		}
+		if i < len(format) && format[i] == 'w' {
+			return true
+		}
+	}
+	return false
+}
+
+// formatVerb is a verb in a format string or "*" width or precision.
+type formatVerb struct {
+	// pos is the index of the verb character (or "*") in the format string.
+	pos int
+	// arg is the index of the argument formatted by the verb (or used as width or precision).
+	arg int
+}
+
+// formatVerbs returns verbs in format with the arguments they format, taking argument indexes (e.g. "%[2]v") and
+// arguments consumed by "*" width or precision into account.
+func formatVerbs(format string) []formatVerb {
+	var verbs []formatVerb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+	directive:
+		for i++; i < len(format); i++ {
+			switch c := format[i]; {
+			case c == '[':
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					return verbs
+				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+					arg = n - 1
+				}
+				i += end
+			case c == '*':
+				verbs = append(verbs, formatVerb{pos: i, arg: arg})
+				arg++
+			case strings.IndexByte("+-# 0123456789.", c) < 0:
+				break directive
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		verbs = append(verbs, formatVerb{pos: i, arg: arg})
+		arg++
 	}
+	return verbs
 }
 
 // Cause returns the result of repeatedly calling the Unwrap method on err, if err's
@@ -113,32 +267,156 @@ func Wrapf(cause error, format string, args ...interface{}) error {
 // actually can be sufficed through the errors.Is function. But considering some use cases
 // where we need to peel off all the external layers applied through errors.Wrap family,
 // it is useful ( where external SDK doesn't use errors.Is internally).
+//
+// Errors wrapping multiple errors with `Unwrap() []error` method (e.g. from merrors or errors.Join) are
+// unwrapped only if they wrap exactly one error. Otherwise, there is no single cause, so Cause returns such error.
 func Cause(err error) error {
 	for err != nil {
-		e, ok := err.(interface {
-			Unwrap() error
-		})
-		if !ok {
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			errs := e.Unwrap()
+			if len(errs) != 1 {
+				return err
+			}
+			err = errs[0]
+		default:
 			return err
 		}
-		err = e.Unwrap()
 	}
 	return nil
 }
 
-// formatErrorChain formats an error chain.
+// Walk calls fn for err and every error in its chain in depth-first order. If errors wrap multiple errors
+// with `Unwrap() []error` method (e.g. from merrors or errors.Join), the chain is a tree and all its
+// branches are visited. The depth is 0 for err and increases by one with each unwrapping.
+// If fn returns false, errors wrapped by the given error are not visited.
+func Walk(err error, fn func(err error, depth int) bool) {
+	walk(err, 0, fn)
+}
+
+func walk(err error, depth int, fn func(err error, depth int) bool) {
+	if err == nil || !fn(err, depth) {
+		return
+	}
+	for _, c := range causes(err) {
+		walk(c, depth+1, fn)
+	}
+}
+
+// causes returns errors wrapped by err, supporting both `Unwrap() error` and `Unwrap() []error` methods.
+// For base errors, errors passed with the %w verb are returned too.
+func causes(err error) []error {
+	if b, ok := err.(*base); ok && len(b.wrapped) > 0 {
+		if b.err == nil {
+			return b.wrapped
+		}
+		return append([]error{b.err}, b.wrapped...)
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if c := e.Unwrap(); c != nil {
+			return []error{c}
+		}
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// StackTrace returns the call frames recorded at the origin of the error, which is the innermost error in the
+// chain created by this package (e.g. with New or Wrap). It returns nil if there is no such error in the chain.
+//
+// To get frames of each wrapped error in the chain, unwrap it and check for the Frames method on each error:
+//
+//	for ; err != nil; err = errors.Unwrap(err) {
+//		if st, ok := err.(interface{ Frames() []errors.Frame }); ok {
+//			// ...
+//		}
+//	}
+func StackTrace(err error) []Frame {
+	var origin *base
+	for err != nil {
+		if b, ok := err.(*base); ok {
+			origin = b
+		}
+		err = Unwrap(err)
+	}
+	if origin == nil {
+		return nil
+	}
+	return origin.Frames()
+}
+
+// formatErrorChain formats an error chain using Formatter set by SetFormatter.
 func formatErrorChain(err error) string {
-	var buf strings.Builder
+	return currentFormatter().Format(err)
+}
+
+// writeErrorChain writes an error chain. Errors wrapping multiple errors are followed by
+// their branches indented with a tab. Frames shared with the stack of the enclosing error (outer) are elided.
+func (f Formatter) writeErrorChain(buf *strings.Builder, err error, indent string, outer []Frame) {
+	// skipMessage is true if the message of the next error in the chain was already printed.
+	skipMessage := false
 	for err != nil {
-		if e, ok := err.(*base); ok {
-			buf.WriteString(fmt.Sprintf("%s\n%v", e.info, e.stack))
-			err = e.err
-		} else {
-			buf.WriteString(fmt.Sprintf("%s\n", err.Error()))
-			err = nil
+		switch e := err.(type) {
+		case *base:
+			frames := e.Frames()
+			msg := e.info
+			if e.stackOnly {
+				// Errors created with WithStack do not have own message, so print the message of the wrapped error
+				// with the stacktrace instead, and skip it when printing the wrapped error.
+				msg = e.err.Error()
+			}
+			if skipMessage {
+				writeIndented(buf, indent, f.formatFrames(frames, outer, e.remote != nil))
+			} else {
+				writeIndented(buf, indent, msg+"\n"+f.formatFrames(frames, outer, e.remote != nil))
+			}
+			skipMessage = e.stackOnly
+			outer = frames
+		case *annotated:
+			// Some annotations (e.g. hints) are printed in separate sections after the whole chain.
+			if line := e.annotation.chainLine(); line != "" {
+				writeIndented(buf, indent, line+"\n")
+			}
+		default:
+			if !skipMessage {
+				writeIndented(buf, indent, fmt.Sprintf("%s\n", err.Error()))
+			}
+			skipMessage = false
+		}
+
+		next := causes(err)
+		if len(next) > 1 {
+			for _, c := range next {
+				f.writeErrorChain(buf, c, indent+"\t", outer)
+			}
+			return
+		}
+
+		err = nil
+		if len(next) == 1 {
+			err = next[0]
 		}
 	}
-	return buf.String()
+}
+
+// writeIndented writes s with each line prefixed with indent.
+func writeIndented(buf *strings.Builder, indent, s string) {
+	if indent == "" {
+		buf.WriteString(s)
+		return
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line == "" {
+			continue
+		}
+		buf.WriteString(indent)
+		buf.WriteString(line)
+	}
 }
 
 // The functions `Is`, `As` & `Unwrap` provides a thin wrapper around the builtin errors
@@ -160,6 +438,44 @@ func As(err error, target interface{}) bool {
 	return errors.As(err, target)
 }
 
+// AsType finds the first error in err's chain that has type T, and if one is found, returns it and true.
+// Otherwise, it returns zero value of T and false. It is a type safe alternative of As, which does not require
+// declaring a target variable, e.g.:
+//
+//	if kerr, ok := errors.AsType[*KeyError](err); ok {
+//		// ...
+//	}
+//
+// Same as for As, T has to match the type the error was created with, so errors with methods on pointer receivers
+// have to be matched with pointer types (e.g. *KeyError). Errors having `As(interface{}) bool` method match if the
+// method returns true for a pointer to T. Errors wrapping multiple errors (e.g. from merrors) are searched in
+// depth-first order, also in Go versions before 1.20.
+func AsType[T error](err error) (T, bool) {
+	var target T
+	found := false
+	Walk(err, func(err error, _ int) bool {
+		if found {
+			return false
+		}
+		if t, ok := err.(T); ok {
+			target, found = t, true
+			return false
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(&target) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return target, found
+}
+
+// HasType reports whether any error in err's chain has type T. See AsType for details.
+func HasType[T error](err error) bool {
+	_, ok := AsType[T](err)
+	return ok
+}
+
 // Unwrap is a wrapper of built-in errors.Unwrap. Unwrap returns the result of
 // calling the Unwrap method on err, if err's type contains an Unwrap method
 // returning error. Otherwise, Unwrap returns nil.
@@ -12,10 +12,13 @@ import (
 	//lint:ignore faillint Custom errors package tests need to import standard library errors.
 	stderrors "errors"
 	"fmt"
+	"io"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
 	"github.com/efficientgo/core/testutil"
 )
 
@@ -166,6 +169,15 @@ func TestCause(t *testing.T) {
 			err:           errors.Wrap(stderrors.New("std-error"), wrapper),
 			expectedCause: "std-error",
 		},
+		{
+			err:           errors.Wrap(merrors.New(stderrors.New("std-error")).Err(), wrapper),
+			expectedCause: "std-error",
+		},
+		{
+			// Multiple causes.
+			err:           errors.Wrap(merrors.New(stderrors.New("std-error"), baseErr).Err(), wrapper),
+			expectedCause: "2 errors: std-error; test_error_message",
+		},
 		{
 			err: nil,
 		},
@@ -181,3 +193,144 @@ func TestCause(t *testing.T) {
 		})
 	}
 }
+
+func TestStackTrace(t *testing.T) {
+	testutil.Equals(t, []errors.Frame(nil), errors.StackTrace(nil))
+	testutil.Equals(t, []errors.Frame(nil), errors.StackTrace(stderrors.New("std-error")))
+
+	baseErr := errors.New(msg)
+	err := errors.Wrap(fmt.Errorf("std wrap: %w", baseErr), wrapper)
+
+	frames := errors.StackTrace(err)
+	testutil.Assert(t, len(frames) > 0)
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.TestStackTrace", frames[0].Function)
+	testutil.Assert(t, strings.HasSuffix(frames[0].File, "/errors/errors_test.go"), "unexpected file %v", frames[0].File)
+
+	// Innermost error frames are returned, which point to the line of errors.New call.
+	wrapperFrames := err.(interface{ Frames() []errors.Frame }).Frames()
+	testutil.Equals(t, frames[0].Line+1, wrapperFrames[0].Line)
+	testutil.Equals(t, frames, baseErr.(interface{ Frames() []errors.Frame }).Frames())
+}
+
+func TestWalk(t *testing.T) {
+	err := errors.Wrap(merrors.New(
+		errors.Wrap(errors.New("a"), "wrapped a"),
+		stderrors.New("b"),
+	).Err(), wrapper)
+
+	var visited []string
+	errors.Walk(err, func(err error, depth int) bool {
+		visited = append(visited, fmt.Sprintf("%d:%s", depth, err.Error()))
+		return err.Error() != "wrapped a: a"
+	})
+	testutil.Equals(t, []string{
+		"0:test_wrapper: 2 errors: wrapped a: a; b",
+		"1:2 errors: wrapped a: a; b",
+		"2:wrapped a: a",
+		"2:b",
+	}, visited)
+}
+
+func TestFormatMultiError(t *testing.T) {
+	err := errors.Wrap(merrors.New(
+		errors.Wrap(errors.New("a"), "wrapped a"),
+		stderrors.New("b"),
+	).Err(), wrapper)
+
+	reg := regexp.MustCompile(`^test_wrapper
+> github\.com\/efficientgo\/core\/errors_test\.TestFormatMultiError	.*\/errors\/errors_test\.go:\d+
+[[:ascii:]]+
+2 errors: wrapped a: a; b
+	wrapped a
+	> github\.com\/efficientgo\/core\/errors_test\.TestFormatMultiError	.*\/errors\/errors_test\.go:\d+
+[[:ascii:]]+
+	a
+	> github\.com\/efficientgo\/core\/errors_test\.TestFormatMultiError	.*\/errors\/errors_test\.go:\d+
+[[:ascii:]]+
+	b
+$`)
+	testutil.Assert(t, reg.MatchString(fmt.Sprintf("%+v", err)), "not matching tree output, got %v", fmt.Sprintf("%+v", err))
+}
+
+type customErr struct{ msg string }
+
+func (c *customErr) Error() string { return c.msg }
+
+func TestNewf_WrapVerb(t *testing.T) {
+	cause := &customErr{msg: "custom"}
+
+	err := errors.Newf("doing %s: %w", "something", cause)
+	testutil.Equals(t, "doing something: custom", err.Error())
+	testutil.Assert(t, errors.Is(err, cause))
+	testutil.Equals(t, error(cause), errors.Unwrap(err))
+
+	var target *customErr
+	testutil.Assert(t, errors.As(err, &target))
+	testutil.Equals(t, cause, target)
+
+	reg := regexp.MustCompile(`^doing something: custom
+> github\.com\/efficientgo\/core\/errors_test\.TestNewf_WrapVerb	.*\/errors\/errors_test\.go:\d+
+[[:ascii:]]+
+custom
+$`)
+	testutil.Assert(t, reg.MatchString(fmt.Sprintf("%+v", err)), "matching stacktrace in errors.Newf, got %v", fmt.Sprintf("%+v", err))
+
+	// Escaped verb is not wrapping.
+	err = errors.Newf("100%%w %v", cause)
+	testutil.Equals(t, "100%w custom", err.Error())
+	testutil.Assert(t, !errors.Is(err, cause))
+}
+
+func TestWrapf_WrapVerb(t *testing.T) {
+	baseErr := errors.New(msg)
+	cause := &customErr{msg: "custom"}
+
+	err := errors.Wrapf(baseErr, "%s with %+w", wrapper, cause)
+	testutil.Equals(t, "test_wrapper with custom: test_error_message", err.Error())
+	testutil.Assert(t, errors.Is(err, baseErr))
+	testutil.Assert(t, errors.Is(err, cause))
+
+	var target *customErr
+	testutil.Assert(t, errors.As(err, &target))
+	testutil.Equals(t, cause, target)
+}
+
+func TestWrapf_WrapVerb_Unwrap(t *testing.T) {
+	baseErr := errors.New(msg)
+	err := errors.Wrapf(baseErr, "%s with %w", wrapper, io.EOF)
+
+	// Wrapped cause is unwrapped first, errors passed with %w are visible to Walk.
+	testutil.Equals(t, baseErr, errors.Unwrap(err))
+	testutil.Equals(t, errors.StackTrace(baseErr), errors.StackTrace(err))
+	testutil.Assert(t, errors.Is(err, io.EOF))
+
+	var visited []error
+	errors.Walk(err, func(err error, _ int) bool {
+		visited = append(visited, err)
+		return true
+	})
+	testutil.Equals(t, []error{err, baseErr, io.EOF}, visited)
+}
+
+type valueErr struct{ code int }
+
+func (v valueErr) Error() string { return fmt.Sprintf("value error %d", v.code) }
+
+func TestAsType(t *testing.T) {
+	cerr := &customErr{msg: msg}
+	err := errors.Wrap(merrors.New(errors.New("a"), valueErr{code: 1}, errors.Wrap(cerr, "b")).Err(), wrapper)
+
+	got, ok := errors.AsType[*customErr](err)
+	testutil.Assert(t, ok)
+	testutil.Assert(t, got == cerr)
+
+	v, ok := errors.AsType[valueErr](err)
+	testutil.Assert(t, ok)
+	testutil.Equals(t, 1, v.code)
+
+	testutil.Assert(t, errors.HasType[valueErr](err))
+	testutil.Assert(t, !errors.HasType[*valueErr](err))
+
+	_, ok = errors.AsType[*customErr](nil)
+	testutil.Assert(t, !ok)
+}
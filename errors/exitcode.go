@@ -0,0 +1,84 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// VerboseEnv is the environment variable, which makes Main print errors with stacktraces when set to non-empty value.
+const VerboseEnv = "ERRORS_VERBOSE"
+
+// WithExitCode returns a new error, which wraps another error with the exit code the process should exit with
+// because of this error, without changing its message. The code can be retrieved with the ExitCode function.
+// The code has to be positive, as an error should never make the process exit successfully, so
+// ExitCode ignores non-positive codes.
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithExitCode(cause error, code int) error {
+	return annotate(cause, exitCodeAnnotation(code))
+}
+
+// ExitCode returns the highest exit code attached with WithExitCode in the error chain (including all branches
+// of errors wrapping multiple errors, e.g. from merrors). It returns 1 if there is none (or all attached codes are
+// not positive) and 0 for nil error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	code := 1
+	for _, c := range annotations[exitCodeAnnotation](err) {
+		if int(c) > code {
+			code = int(c)
+		}
+	}
+	return code
+}
+
+// Main runs f, which is meant to be the body of the main function of CLI tool. If f returns error, Main prints it
+// to the standard error with hints attached with WithHint and exits the process with the code returned by ExitCode.
+// If VerboseEnv environment variable is set, the error is printed with "%+v" instead, so with stacktraces. Otherwise,
+// Main returns normally, e.g.:
+//
+//	func main() {
+//		errors.Main(func() error {
+//			// ...
+//		})
+//	}
+func Main(f func() error) {
+	if code := runMain(f, os.Stderr, os.Getenv); code != 0 {
+		os.Exit(code)
+	}
+}
+
+func runMain(f func() error, stderr io.Writer, getenv func(string) string) int {
+	err := f()
+	if err == nil {
+		return 0
+	}
+
+	if getenv(VerboseEnv) != "" {
+		_, _ = fmt.Fprintf(stderr, "%+v", err)
+	} else {
+		_, _ = fmt.Fprintf(stderr, "error: %s\n", err.Error())
+		for _, h := range Hints(err) {
+			_, _ = fmt.Fprintf(stderr, "hint: %s\n", h)
+		}
+	}
+	return ExitCode(err)
+}
+
+// exitCodeAnnotation holds exit code attached with WithExitCode.
+type exitCodeAnnotation int
+
+func (exitCodeAnnotation) kind() string { return kindExitCode }
+
+func (c exitCodeAnnotation) chainLine() string { return "exit code: " + strconv.Itoa(int(c)) }
+
+func (c exitCodeAnnotation) setJSON(j *jsonError) { j.ExitCode = int(c) }
+
+func (c exitCodeAnnotation) setEncoded(e *encodedError) { e.ExitCode = int(c) }
@@ -0,0 +1,58 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestExitCode(t *testing.T) {
+	testutil.Ok(t, errors.WithExitCode(nil, 2))
+	testutil.Equals(t, 0, errors.ExitCode(nil))
+	testutil.Equals(t, 1, errors.ExitCode(errors.New(msg)))
+
+	err := errors.Wrap(errors.WithExitCode(errors.New(msg), 3), wrapper)
+	testutil.Equals(t, wrapper+": "+msg, err.Error())
+	testutil.Equals(t, 3, errors.ExitCode(err))
+	testutil.Equals(t, 3, errors.ExitCode(errors.Decode(errors.Encode(err))))
+	testutil.Assert(t, strings.Contains(fmt.Sprintf("%+v", err), "\nexit code: 3\n"))
+
+	testutil.Equals(t, 4, errors.ExitCode(merrors.New(
+		errors.WithExitCode(errors.New("a"), 2),
+		errors.New("b"),
+		errors.WithExitCode(errors.New("c"), 4),
+	).Err()))
+	// Error never makes the process exit successfully.
+	testutil.Equals(t, 1, errors.ExitCode(errors.WithExitCode(errors.New(msg), 0)))
+	testutil.Equals(t, 1, errors.ExitCode(errors.WithExitCode(errors.New(msg), -2)))
+}
+
+func TestRunMain(t *testing.T) {
+	f := func() error {
+		return errors.WithHint(errors.WithExitCode(errors.New(msg), 2), "try again")
+	}
+
+	var stderr bytes.Buffer
+	testutil.Equals(t, 0, errors.RunMain(func() error { return nil }, &stderr, func(string) string { return "" }))
+	testutil.Equals(t, "", stderr.String())
+
+	testutil.Equals(t, 2, errors.RunMain(f, &stderr, func(string) string { return "" }))
+	testutil.Equals(t, "error: "+msg+"\nhint: try again\n", stderr.String())
+
+	stderr.Reset()
+	testutil.Equals(t, 2, errors.RunMain(f, &stderr, func(key string) string {
+		testutil.Equals(t, errors.VerboseEnv, key)
+		return "1"
+	}))
+	out := stderr.String()
+	testutil.Assert(t, strings.HasPrefix(out, "exit code: 2\n"+msg+"\n> github.com/efficientgo/core/errors_test.TestRunMain"), out)
+	testutil.Assert(t, strings.HasSuffix(out, "hints:\n  - try again\n"), out)
+}
@@ -0,0 +1,7 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+// RunMain exposes runMain for tests, so Main can be tested without exiting the process.
+var RunMain = runMain
@@ -0,0 +1,71 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// missingValue is used when odd number of key-values is passed, same as go-kit/log does.
+const missingValue = "(MISSING)"
+
+// WithFields returns a new error, which wraps another error with structured key-value pairs (e.g. "tenant", tenantID)
+// without changing its message. Fields can be retrieved with the Fields function and are printed with "%+v".
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithFields(cause error, keyvals ...interface{}) error {
+	return annotate(cause, newFieldsAnnotation(keyvals))
+}
+
+// newFieldsAnnotation returns fields annotation with missingValue appended if odd number of key-values is passed.
+func newFieldsAnnotation(keyvals []interface{}) fieldsAnnotation {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, missingValue)
+	}
+	return fieldsAnnotation(keyvals)
+}
+
+// Fields returns all key-value pairs attached with WithFields along the error chain (including all branches
+// of errors wrapping multiple errors), from the outermost error.
+func Fields(err error) []interface{} {
+	var fields []interface{}
+	for _, f := range annotations[fieldsAnnotation](err) {
+		fields = append(fields, f...)
+	}
+	return fields
+}
+
+// fieldsAnnotation holds key-value pairs attached with WithFields.
+type fieldsAnnotation []interface{}
+
+func (fieldsAnnotation) kind() string { return kindFields }
+
+func (f fieldsAnnotation) chainLine() string { return "fields: " + formatFields(f) }
+
+func (f fieldsAnnotation) setJSON(j *jsonError) {
+	j.Fields = make(map[string]interface{}, len(f)/2)
+	for i := 0; i < len(f); i += 2 {
+		j.Fields[fmt.Sprintf("%v", f[i])] = jsonFieldValue(f[i+1])
+	}
+}
+
+func (f fieldsAnnotation) setEncoded(e *encodedError) {
+	e.Fields = make([]interface{}, 0, len(f))
+	for _, v := range f {
+		e.Fields = append(e.Fields, jsonFieldValue(v))
+	}
+}
+
+// formatFields formats key-value pairs as space separated key=value list.
+func formatFields(fields []interface{}) string {
+	var buf strings.Builder
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
+	}
+	return buf.String()
+}
@@ -0,0 +1,43 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func ExampleWithFields() {
+	err := errors.WithFields(errors.New("block not found"), "tenant", "team-a", "block", "01H")
+	err = errors.WithFields(errors.Wrap(err, "compacting"), "attempt", 2)
+
+	fmt.Println(err)
+	fmt.Println(errors.Fields(err)...)
+
+	// Output: compacting: block not found
+	// attempt 2 tenant team-a block 01H
+}
+
+func TestWithFields(t *testing.T) {
+	testutil.Ok(t, errors.WithFields(nil, "tenant", "a"))
+
+	baseErr := errors.New(msg)
+	err := errors.WithFields(baseErr, "tenant", "a", "block")
+	testutil.Equals(t, msg, err.Error())
+	testutil.Assert(t, errors.Is(err, baseErr))
+	testutil.Equals(t, []interface{}{"tenant", "a", "block", "(MISSING)"}, errors.Fields(err))
+
+	err = errors.Wrap(err, wrapper)
+	testutil.Equals(t, []interface{}{"tenant", "a", "block", "(MISSING)"}, errors.Fields(err))
+	testutil.Equals(t, []interface{}(nil), errors.Fields(baseErr))
+
+	reg := regexp.MustCompile(`test_wrapper[ \n]+> github\.com\/efficientgo\/core\/errors_test\.TestWithFields	.*\/errors\/fields_test\.go:\d+
+[[:ascii:]]+fields: tenant=a block=\(MISSING\)
+test_error_message[ \n]+> github\.com\/efficientgo\/core\/errors_test\.TestWithFields	.*\/errors\/fields_test\.go:\d+`)
+	testutil.Assert(t, reg.MatchString(fmt.Sprintf("%+v", err)), "not matching fields in %+v output", fmt.Sprintf("%+v", err))
+}
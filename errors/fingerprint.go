@@ -0,0 +1,93 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+)
+
+// Fingerprint returns a stable identifier of the error, which is the same for all occurrences of "the same" error,
+// even if their messages contain different values, e.g. Newf("block %s not found", id). It can be used to
+// group or deduplicate errors, e.g. in error dashboards.
+//
+// The fingerprint is a hash of the error chain structure. Errors created by this package contribute their format
+// string passed to Newf or Wrapf (or the message passed to New or Wrap) and the name of the function which created
+// them. Codes attached with WithCode are included, but fields, hints and details are not. Registered sentinel
+// errors and Sentinel errors contribute their names, other errors contribute their type, and their message only if they do not wrap
+// other errors. It returns empty string for nil error.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	registry.mtx.RLock()
+	defer registry.mtx.RUnlock()
+
+	h := fnv.New64a()
+	writeFingerprint(h, err)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// writeFingerprint writes parts identifying each error in the chain. Branches of errors wrapping
+// multiple errors are enclosed in brackets, so the structure of the chain is included.
+func writeFingerprint(w io.Writer, err error) {
+	for err != nil {
+		for _, part := range fingerprintParts(err) {
+			_, _ = w.Write([]byte(part))
+			_, _ = w.Write([]byte{0})
+		}
+
+		cs := causes(err)
+		if len(cs) > 1 {
+			for _, c := range cs {
+				_, _ = w.Write([]byte{'('})
+				writeFingerprint(w, c)
+				_, _ = w.Write([]byte{')'})
+			}
+			return
+		}
+
+		err = nil
+		if len(cs) == 1 {
+			err = cs[0]
+		}
+	}
+}
+
+// fingerprintParts returns parts of the error identifying it, without the errors it wraps. Annotations which
+// do not identify the error (e.g. fields) return no parts.
+func fingerprintParts(err error) []string {
+	if name, ok := registeredName(err); ok {
+		return []string{"sentinel", name}
+	}
+
+	switch e := err.(type) {
+	case Sentinel:
+		return []string{"sentinel", string(e)}
+	case *base:
+		template := e.format
+		if template == "" {
+			template = e.info
+		}
+		var origin string
+		if frames := e.Frames(); len(frames) > 0 {
+			origin = frames[0].Function
+		}
+		return []string{"base", template, origin}
+	case *annotated:
+		if c, ok := e.annotation.(codeAnnotation); ok {
+			return []string{"code", ErrorCode(c).String()}
+		}
+		return nil
+	}
+
+	parts := []string{"type", reflect.TypeOf(err).String()}
+	if len(causes(err)) == 0 {
+		parts = append(parts, err.Error())
+	}
+	return parts
+}
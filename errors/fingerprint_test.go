@@ -0,0 +1,43 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	//lint:ignore faillint Custom errors package tests need to import standard library errors.
+	stderrors "errors"
+	"io"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func blockNotFound(id string) error {
+	return errors.Wrap(errors.Newf("block %s not found", id), "syncing")
+}
+
+func TestFingerprint(t *testing.T) {
+	testutil.Equals(t, "", errors.Fingerprint(nil))
+
+	fp := errors.Fingerprint(blockNotFound("01H1"))
+	testutil.Equals(t, 16, len(fp))
+	testutil.Equals(t, fp, errors.Fingerprint(blockNotFound("01H2")))
+	testutil.Equals(t, fp, errors.Fingerprint(errors.WithFields(blockNotFound("01H3"), "tenant", "a")))
+	testutil.Equals(t, fp, errors.Fingerprint(errors.Decode(errors.Encode(blockNotFound("01H4")))))
+
+	for _, other := range []error{
+		errors.Wrap(errors.Newf("block %s not found", "01H1"), "syncing"),
+		errors.Wrap(blockNotFound("01H1"), wrapper),
+		errors.WithCode(blockNotFound("01H1"), errors.NotFound),
+		errors.Newf("block %s not found", "01H1"),
+	} {
+		testutil.Assert(t, fp != errors.Fingerprint(other), "expected different fingerprint for %v", other)
+	}
+
+	t.Run("foreign errors", func(t *testing.T) {
+		testutil.Equals(t, errors.Fingerprint(stderrors.New("a")), errors.Fingerprint(stderrors.New("a")))
+		testutil.Assert(t, errors.Fingerprint(stderrors.New("a")) != errors.Fingerprint(stderrors.New("b")))
+		testutil.Assert(t, errors.Fingerprint(errors.Wrap(io.EOF, "reading")) != errors.Fingerprint(errors.Wrap(io.ErrUnexpectedEOF, "reading")))
+	})
+}
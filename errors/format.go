@@ -0,0 +1,201 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Formatter configures how error chains with stacktraces are printed. The zero value prints frames with
+// function names and absolute file paths, eliding frames already printed for the enclosing error.
+type Formatter struct {
+	// TrimPaths replaces absolute file paths (which contain GOPATH, module cache or build machine directories) with
+	// the package path and file name, e.g. "github.com/efficientgo/core/errors/errors.go".
+	TrimPaths bool
+	// TrimPrefixes are removed from file paths (after TrimPaths is applied), e.g. to remove build machine paths.
+	TrimPrefixes []string
+	// SkipFunctions are path.Match patterns of function names, frames of which are not printed,
+	// e.g. "runtime.*", "testing.*" or "github.com/efficientgo/core/errors.*".
+	SkipFunctions []string
+	// Compact prints frames of each error in a single line, with short function names and file names only, e.g.
+	// "> errors_test.TestNew(errors_test.go:21) < testing.tRunner(testing.go:1259)".
+	Compact bool
+	// FullStacks disables eliding of frames shared with the stacktrace of the enclosing error in the chain.
+	// By default, for errors created in the same call path (e.g. multiple Wrap calls), only frames not present in the
+	// enclosing error stacktrace are printed, followed by "... N frames elided" line. Errors created in different
+	// goroutines share no meaningful frames, so their stacktraces are printed in full.
+	FullStacks bool
+}
+
+// formatter stores the Formatter set by SetFormatter.
+var formatter atomic.Value
+
+// SetFormatter sets the Formatter used when errors from this package are printed with "%+v".
+// It is safe to call it concurrently with formatting errors.
+func SetFormatter(f Formatter) {
+	formatter.Store(f)
+}
+
+func currentFormatter() Formatter {
+	f, _ := formatter.Load().(Formatter)
+	return f
+}
+
+// Format returns err formatted the same as "%+v" prints it, but using the f settings. The error chain
+// is followed by details and hints attached with WithDetail and WithHint, if any.
+func (f Formatter) Format(err error) string {
+	var buf strings.Builder
+	f.writeErrorChain(&buf, err, "", nil)
+	writeSection(&buf, "details", Details(err))
+	writeSection(&buf, "hints", Hints(err))
+	return buf.String()
+}
+
+// formatFrames formats frames. Frames recorded in a different process are marked as remote.
+// Trailing frames shared with outer frames are elided, unless FullStacks is set.
+func (f Formatter) formatFrames(frames, outer []Frame, remote bool) string {
+	elided := 0
+	if !f.FullStacks {
+		elided = sharedFrames(frames, outer)
+	}
+
+	var buf strings.Builder
+	n := 0
+	for _, fr := range frames[:len(frames)-elided] {
+		if f.skip(fr) {
+			continue
+		}
+
+		if f.Compact {
+			if n == 0 {
+				buf.WriteString("> ")
+			} else {
+				buf.WriteString(" < ")
+			}
+			buf.WriteString(shortFunction(fr.Function))
+			buf.WriteByte('(')
+			buf.WriteString(path.Base(fr.File))
+			buf.WriteByte(':')
+			buf.WriteString(strconv.Itoa(fr.Line))
+			buf.WriteByte(')')
+		} else {
+			writeFrame(&buf, fr.Function, f.trimPath(fr), fr.Line)
+			if remote {
+				buf.WriteString(" (remote)")
+			}
+			buf.WriteByte('\n')
+		}
+		n++
+	}
+	if f.Compact {
+		if elided > 0 {
+			buf.WriteString(" ... ")
+			buf.WriteString(framesElided(elided))
+		}
+		if n > 0 || elided > 0 {
+			if remote {
+				buf.WriteString(" (remote)")
+			}
+			buf.WriteByte('\n')
+		}
+		return buf.String()
+	}
+	if elided > 0 {
+		buf.WriteString("... ")
+		buf.WriteString(framesElided(elided))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func framesElided(n int) string {
+	if n == 1 {
+		return "1 frame elided"
+	}
+	return strconv.Itoa(n) + " frames elided"
+}
+
+// sharedFrames returns the number of trailing frames, which are present in outer frames too. Both stacktraces
+// are truncated to the same depth, so the longest tail of frames contained in outer as a contiguous
+// sequence is searched for, not only the common suffix. The first frame, where the error was created, is never elided.
+// Frames are not elided if only runtime frames are shared (e.g. runtime.goexit for errors created in other goroutine),
+// as then the error was not created in the same call path.
+func sharedFrames(frames, outer []Frame) int {
+	if len(outer) == 0 {
+		return 0
+	}
+	for i := 1; i < len(frames); i++ {
+		if !containsFrames(outer, frames[i:]) {
+			continue
+		}
+		for _, fr := range frames[i:] {
+			if !strings.HasPrefix(fr.Function, "runtime.") {
+				return len(frames) - i
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// containsFrames returns true if frames contains seq as a contiguous sequence.
+func containsFrames(frames, seq []Frame) bool {
+	for i := 0; i+len(seq) <= len(frames); i++ {
+		match := true
+		for j := range seq {
+			if frames[i+j] != seq[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Formatter) skip(fr Frame) bool {
+	for _, pattern := range f.SkipFunctions {
+		if ok, _ := path.Match(pattern, fr.Function); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Formatter) trimPath(fr Frame) string {
+	file := fr.File
+	if f.TrimPaths {
+		if pkg := packagePath(fr.Function); pkg != "" {
+			file = pkg + "/" + path.Base(file)
+		}
+	}
+	for _, prefix := range f.TrimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			file = strings.TrimPrefix(file[len(prefix):], "/")
+			break
+		}
+	}
+	return file
+}
+
+// packagePath returns the package path of the function name, e.g. "github.com/efficientgo/core/errors" for
+// "github.com/efficientgo/core/errors.(*base).Error". External test packages are reported as the tested package.
+func packagePath(function string) string {
+	lastSlash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[lastSlash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(function[:lastSlash+1+dot], "_test")
+}
+
+// shortFunction returns the function name without the package path prefix, e.g. "errors.(*base).Error".
+func shortFunction(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
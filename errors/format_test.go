@@ -0,0 +1,86 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestFormatter(t *testing.T) {
+	err := errors.Wrap(errors.New(msg), wrapper)
+
+	t.Run("zero value prints the same as %+v", func(t *testing.T) {
+		testutil.Equals(t, fmt.Sprintf("%+v", err), errors.Formatter{}.Format(err))
+	})
+	t.Run("trim paths", func(t *testing.T) {
+		out := errors.Formatter{TrimPaths: true}.Format(err)
+		testutil.Assert(t, strings.Contains(out, "> github.com/efficientgo/core/errors_test.TestFormatter\tgithub.com/efficientgo/core/errors/format_test.go:"), out)
+		testutil.Assert(t, strings.Contains(out, "\ttesting/testing.go:"), out)
+	})
+	t.Run("trim prefixes", func(t *testing.T) {
+		out := errors.Formatter{TrimPaths: true, TrimPrefixes: []string{"github.com/efficientgo/core"}}.Format(err)
+		testutil.Assert(t, strings.Contains(out, "\terrors/format_test.go:"), out)
+	})
+	t.Run("skip functions", func(t *testing.T) {
+		out := errors.Formatter{SkipFunctions: []string{"runtime.*", "testing.*"}}.Format(err)
+		testutil.Assert(t, !strings.Contains(out, "> testing."), out)
+		testutil.Assert(t, !strings.Contains(out, "> runtime."), out)
+		testutil.Equals(t, 2, strings.Count(out, "> github.com/efficientgo/core/errors_test.TestFormatter"), out)
+	})
+	t.Run("compact", func(t *testing.T) {
+		out := errors.Formatter{Compact: true, SkipFunctions: []string{"runtime.*"}}.Format(err)
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		testutil.Equals(t, 4, len(lines), out)
+		testutil.Equals(t, wrapper, lines[0])
+		testutil.Assert(t, strings.HasPrefix(lines[1], "> errors_test.TestFormatter(format_test.go:"), out)
+		testutil.Assert(t, strings.Contains(lines[1], " < testing.tRunner(testing.go:"), out)
+		testutil.Equals(t, msg, lines[2])
+	})
+	t.Run("global formatter", func(t *testing.T) {
+		errors.SetFormatter(errors.Formatter{SkipFunctions: []string{"testing.*"}})
+		defer errors.SetFormatter(errors.Formatter{})
+
+		testutil.Assert(t, !strings.Contains(fmt.Sprintf("%+v", err), "> testing."))
+	})
+}
+
+func newNestedErr() error {
+	return errors.New(msg)
+}
+
+func TestFormatter_ElideSharedFrames(t *testing.T) {
+	err := errors.Wrap(newNestedErr(), wrapper)
+
+	out := fmt.Sprintf("%+v", err)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	testutil.Equals(t, wrapper, lines[0], out)
+	testutil.Equals(t, msg, lines[len(lines)-3], out)
+	testutil.Assert(t, strings.HasPrefix(lines[len(lines)-2], "> github.com/efficientgo/core/errors_test.newNestedErr\t"), out)
+	// Test function, tRunner and goexit frames are shared with the wrapper.
+	testutil.Equals(t, "... 3 frames elided", lines[len(lines)-1], out)
+
+	full := errors.Formatter{FullStacks: true}.Format(err)
+	testutil.Assert(t, !strings.Contains(full, "elided"), full)
+	testutil.Equals(t, 2, strings.Count(full, "> testing.tRunner"), full)
+
+	compact := errors.Formatter{Compact: true}.Format(err)
+	testutil.Assert(t, regexp.MustCompile(`\n> errors_test\.newNestedErr\(format_test\.go:\d+\) \.\.\. 3 frames elided\n$`).MatchString(compact), compact)
+}
+
+func TestFormatter_ElideSharedFrames_OtherGoroutine(t *testing.T) {
+	errc := make(chan error)
+	go func() { errc <- newNestedErr() }()
+	err := errors.Wrap(<-errc, wrapper)
+
+	// Only runtime.goexit frame is shared, so nothing is elided.
+	out := fmt.Sprintf("%+v", err)
+	testutil.Assert(t, !strings.Contains(out, "elided"), out)
+	testutil.Equals(t, 2, strings.Count(out, "> runtime.goexit"), out)
+}
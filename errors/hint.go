@@ -0,0 +1,80 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import "strings"
+
+// WithHint returns a new error, which wraps another error with an actionable hint for the user (e.g. "check bucket
+// permissions"), without changing its message. Hints can be retrieved with the Hints function and are printed
+// with "%+v" in a separate section.
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithHint(cause error, hint string) error {
+	return annotate(cause, hintAnnotation(hint))
+}
+
+// WithDetail returns a new error, which wraps another error with a detail for the user (e.g. which configuration
+// was used), without changing its message. Details can be retrieved with the Details function and are printed
+// with "%+v" in a separate section.
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithDetail(cause error, detail string) error {
+	return annotate(cause, detailAnnotation(detail))
+}
+
+// Hints returns all hints attached with WithHint along the error chain (including all branches
+// of errors wrapping multiple errors), from the outermost error.
+func Hints(err error) []string {
+	var hints []string
+	for _, h := range annotations[hintAnnotation](err) {
+		hints = append(hints, string(h))
+	}
+	return hints
+}
+
+// Details returns all details attached with WithDetail along the error chain (including all branches
+// of errors wrapping multiple errors), from the outermost error.
+func Details(err error) []string {
+	var details []string
+	for _, d := range annotations[detailAnnotation](err) {
+		details = append(details, string(d))
+	}
+	return details
+}
+
+// hintAnnotation holds hint attached with WithHint. Hints are printed in a separate section, not in the error chain.
+type hintAnnotation string
+
+func (hintAnnotation) kind() string { return kindHint }
+
+func (hintAnnotation) chainLine() string { return "" }
+
+func (h hintAnnotation) setJSON(j *jsonError) { j.Hint = string(h) }
+
+func (h hintAnnotation) setEncoded(e *encodedError) { e.Message = string(h) }
+
+// detailAnnotation holds detail attached with WithDetail. Details are printed in a separate section, not in the error chain.
+type detailAnnotation string
+
+func (detailAnnotation) kind() string { return kindDetail }
+
+func (detailAnnotation) chainLine() string { return "" }
+
+func (d detailAnnotation) setJSON(j *jsonError) { j.Detail = string(d) }
+
+func (d detailAnnotation) setEncoded(e *encodedError) { e.Message = string(d) }
+
+// writeSection writes labelled list of messages, e.g. hints, if there are any.
+func writeSection(buf *strings.Builder, label string, msgs []string) {
+	if len(msgs) == 0 {
+		return
+	}
+	buf.WriteString(label)
+	buf.WriteString(":\n")
+	for _, m := range msgs {
+		buf.WriteString("  - ")
+		buf.WriteString(m)
+		buf.WriteByte('\n')
+	}
+}
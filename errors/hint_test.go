@@ -0,0 +1,53 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func ExampleWithHint() {
+	err := errors.WithDetail(errors.New("access denied"), "bucket: metrics-eu")
+	err = errors.WithHint(errors.Wrap(err, "uploading block"), "check bucket permissions")
+
+	fmt.Println(err)
+	fmt.Println(errors.Details(err), errors.Hints(err))
+
+	// Output: uploading block: access denied
+	// [bucket: metrics-eu] [check bucket permissions]
+}
+
+func TestWithHintAndDetail(t *testing.T) {
+	testutil.Ok(t, errors.WithHint(nil, "hint"))
+	testutil.Ok(t, errors.WithDetail(nil, "detail"))
+
+	baseErr := errors.New(msg)
+	err := errors.WithHint(errors.WithDetail(baseErr, "detail a"), "hint a")
+	err = errors.WithHint(errors.Wrap(err, wrapper), "hint b")
+	testutil.Equals(t, wrapper+": "+msg, err.Error())
+	testutil.Assert(t, errors.Is(err, baseErr))
+	testutil.Equals(t, []string{"hint b", "hint a"}, errors.Hints(err))
+	testutil.Equals(t, []string{"detail a"}, errors.Details(err))
+	testutil.Equals(t, []string(nil), errors.Hints(baseErr))
+
+	out := fmt.Sprintf("%+v", err)
+	testutil.Assert(t, strings.HasPrefix(out, wrapper+"\n"), out)
+	testutil.Assert(t, strings.HasSuffix(out, "\ndetails:\n  - detail a\nhints:\n  - hint b\n  - hint a\n"), out)
+	testutil.Equals(t, wrapper+": "+msg, errors.Redacted(err))
+
+	b, jerr := json.Marshal(err)
+	testutil.Ok(t, jerr)
+	testutil.Assert(t, strings.HasPrefix(string(b), `{"hint":"hint b","cause":{"message":"test_wrapper"`), string(b))
+
+	decoded := errors.Decode(errors.Encode(err))
+	testutil.Equals(t, err.Error(), decoded.Error())
+	testutil.Equals(t, errors.Hints(err), errors.Hints(decoded))
+	testutil.Equals(t, errors.Details(err), errors.Details(decoded))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is called every time an error is created with New, Newf, Wrap, Wrapf, WithStack, Recover or FromPanic. It
+// receives the error message (without the wrapped error message, so empty for WithStack), the frame of the function
+// which created the error (the panicking function for Recover and FromPanic, if stacktraces are enabled) and the
+// wrapped error as returned by Unwrap (e.g. the first error passed with %w to Newf, nil for New). Hooks are called
+// synchronously, so they should be fast, e.g. increment a counter by the caller function or sample errors to a
+// tracing backend.
+type Hook func(message string, caller Frame, cause error)
+
+type hookEntry struct {
+	hook Hook
+}
+
+var (
+	hooksMtx sync.Mutex
+	// hooks stores []*hookEntry, which is replaced on every change, so it can be read without locking.
+	hooks atomic.Value
+)
+
+// RegisterHook registers hook called every time an error is created. It returns a function which unregisters
+// the hook. When no hooks are registered, creating errors costs only one atomic load more.
+func RegisterHook(hook Hook) (unregister func()) {
+	e := &hookEntry{hook: hook}
+
+	hooksMtx.Lock()
+	defer hooksMtx.Unlock()
+
+	current, _ := hooks.Load().([]*hookEntry)
+	hooks.Store(append(append(make([]*hookEntry, 0, len(current)+1), current...), e))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hooksMtx.Lock()
+			defer hooksMtx.Unlock()
+
+			current, _ := hooks.Load().([]*hookEntry)
+			updated := make([]*hookEntry, 0, len(current))
+			for _, c := range current {
+				if c != e {
+					updated = append(updated, c)
+				}
+			}
+			hooks.Store(updated)
+		})
+	}
+}
+
+// runHooks calls registered hooks for the error created by the function calling runHooks.
+func runHooks(b *base) {
+	entries, _ := hooks.Load().([]*hookEntry)
+	if len(entries) == 0 {
+		return
+	}
+
+	var pc [1]uintptr
+	// Skip runtime.Callers, runHooks and the function creating the error.
+	callHooks(entries, b, pc[:runtime.Callers(3, pc[:])])
+}
+
+// runPanicHooks calls registered hooks for the error created from panic, with the panicking function as the caller.
+func runPanicHooks(b *base) {
+	entries, _ := hooks.Load().([]*hookEntry)
+	if len(entries) == 0 {
+		return
+	}
+
+	var pc []uintptr
+	if len(b.stack) > 0 {
+		pc = b.stack[:1]
+	}
+	callHooks(entries, b, pc)
+}
+
+func callHooks(entries []*hookEntry, b *base, pc []uintptr) {
+	var caller Frame
+	if len(pc) > 0 {
+		frame, _ := runtime.CallersFrames(pc).Next()
+		caller = Frame{Function: frame.Function, File: frame.File, Line: frame.Line}
+	}
+	for _, e := range entries {
+		e.hook(b.info, caller, b.Unwrap())
+	}
+}
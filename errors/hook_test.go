@@ -0,0 +1,78 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+type hookCall struct {
+	message string
+	caller  errors.Frame
+	cause   error
+}
+
+func TestRegisterHook(t *testing.T) {
+	var calls []hookCall
+	unregister := errors.RegisterHook(func(message string, caller errors.Frame, cause error) {
+		calls = append(calls, hookCall{message: message, caller: caller, cause: cause})
+	})
+
+	baseErr := errors.New(msg)
+	_ = errors.Wrapf(baseErr, "%s %d", wrapper, 1)
+	_ = errors.Wrap(nil, wrapper)
+	_ = errors.Newf("%s: %w", wrapper, baseErr)
+
+	testutil.Equals(t, 3, len(calls))
+	testutil.Equals(t, msg, calls[0].message)
+	testutil.Equals(t, nil, calls[0].cause)
+	testutil.Equals(t, wrapper+" 1", calls[1].message)
+	testutil.Assert(t, calls[1].cause == baseErr)
+	testutil.Equals(t, wrapper+": "+msg, calls[2].message)
+	testutil.Assert(t, calls[2].cause == baseErr)
+	for _, c := range calls {
+		testutil.Equals(t, "github.com/efficientgo/core/errors_test.TestRegisterHook", c.caller.Function)
+		testutil.Assert(t, strings.HasSuffix(c.caller.File, "/errors/hook_test.go"), c.caller.File)
+	}
+
+	unregister()
+	unregister()
+	_ = errors.Newf("%s", msg)
+	testutil.Equals(t, 3, len(calls))
+}
+
+func TestRegisterHook_Panic(t *testing.T) {
+	var calls []hookCall
+	defer errors.RegisterHook(func(message string, caller errors.Frame, cause error) {
+		calls = append(calls, hookCall{message: message, caller: caller, cause: cause})
+	})()
+
+	_ = func() (err error) {
+		defer errors.Recover(&err)
+		panicWith("something bad")
+		return nil
+	}()
+	_ = func() (err error) {
+		defer func() {
+			err = errors.FromPanic(recover())
+		}()
+		panicWith(io.EOF)
+		return nil
+	}()
+
+	testutil.Equals(t, 2, len(calls))
+	testutil.Equals(t, "panic: something bad", calls[0].message)
+	testutil.Equals(t, nil, calls[0].cause)
+	testutil.Equals(t, "panic", calls[1].message)
+	testutil.Assert(t, calls[1].cause == io.EOF)
+	for _, c := range calls {
+		// Caller is the panicking function.
+		testutil.Equals(t, "github.com/efficientgo/core/errors_test.panicWith", c.caller.Function)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+//go:build go1.20
+
+package errors_test
+
+import (
+	//lint:ignore faillint Custom errors package tests need to import standard library errors.
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestStdMultiErrors(t *testing.T) {
+	a, b := errors.New("a"), errors.New("b")
+
+	for _, err := range []error{
+		stderrors.Join(a, b),
+		fmt.Errorf("both: %w, %w", a, b),
+	} {
+		testutil.Equals(t, err, errors.Cause(err))
+
+		var visited []error
+		errors.Walk(err, func(err error, _ int) bool {
+			visited = append(visited, err)
+			return true
+		})
+		testutil.Equals(t, []error{err, a, b}, visited)
+
+		// Both branches are printed with their stacktraces.
+		out := fmt.Sprintf("%+v", errors.Wrap(err, wrapper))
+		testutil.Assert(t, strings.Contains(out, "\ta\n\t> github.com/efficientgo/core/errors_test.TestStdMultiErrors"), out)
+		testutil.Assert(t, strings.Contains(out, "\tb\n\t> github.com/efficientgo/core/errors_test.TestStdMultiErrors"), out)
+	}
+}
+
+func TestNewf_MultipleWrapVerbs(t *testing.T) {
+	a, b := errors.New("a"), &customErr{msg: "b"}
+
+	err := errors.Newf("both %w and %w", a, b)
+	testutil.Equals(t, "both a and b", err.Error())
+	// The first error passed with %w is unwrapped, the other is matched by Is and As.
+	testutil.Equals(t, a, errors.Unwrap(err))
+	testutil.Equals(t, errors.StackTrace(a), errors.StackTrace(err))
+	testutil.Assert(t, errors.Is(err, a))
+	testutil.Assert(t, errors.Is(err, b))
+
+	var target *customErr
+	testutil.Assert(t, errors.As(err, &target))
+	testutil.Equals(t, b, target)
+
+	out := fmt.Sprintf("%+v", err)
+	testutil.Assert(t, strings.HasPrefix(out, "both a and b\n> github.com/efficientgo/core/errors_test.TestNewf_MultipleWrapVerbs"), out)
+	testutil.Assert(t, strings.Contains(out, "\ta\n\t> github.com/efficientgo/core/errors_test.TestNewf_MultipleWrapVerbs"), out)
+	testutil.Assert(t, strings.HasSuffix(out, "\tb\n"), out)
+}
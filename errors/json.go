@@ -0,0 +1,81 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonError is the JSON representation of a single error in the chain.
+type jsonError struct {
+	Message  string                 `json:"message,omitempty"`
+	Code     string                 `json:"code,omitempty"`
+	ExitCode int                    `json:"exitCode,omitempty"`
+	Hint     string                 `json:"hint,omitempty"`
+	Detail   string                 `json:"detail,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Stack    []Frame                `json:"stack,omitempty"`
+	// Remote is true if stack was recorded in a different process (see Decode).
+	Remote bool `json:"remote,omitempty"`
+	// Cause is set if error wraps a single error.
+	Cause interface{} `json:"cause,omitempty"`
+	// Causes is set if error wraps multiple errors.
+	Causes []interface{} `json:"causes,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The error is marshaled as a JSON object with
+// "message" and "stack" frames and the wrapped error (if any) nested as "cause" (or "causes" for multiple errors).
+func (b *base) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONError(b))
+}
+
+func newJSONError(err error) *jsonError {
+	j := &jsonError{}
+	switch e := err.(type) {
+	case *base:
+		j.Message = e.info
+		j.Stack = e.Frames()
+		j.Remote = e.remote != nil
+	case *annotated:
+		e.annotation.setJSON(j)
+	default:
+		j.Message = err.Error()
+	}
+
+	cs := causes(err)
+	if len(cs) == 1 {
+		j.Cause = jsonCause(cs[0])
+		return j
+	}
+	for _, c := range cs {
+		j.Causes = append(j.Causes, jsonCause(c))
+	}
+	return j
+}
+
+// jsonCause returns value to marshal for the wrapped error. Errors implementing json.Marshaler (e.g. from this
+// package or merrors) marshal themselves, other errors are marshaled as jsonError with their message.
+func jsonCause(err error) interface{} {
+	if _, ok := err.(json.Marshaler); ok {
+		return err
+	}
+	return newJSONError(err)
+}
+
+// jsonFieldValue returns field value, which can be marshaled to JSON, so a single field cannot fail marshaling of
+// the whole error. Values that cannot be marshaled (e.g. channels or functions) are printed with "%v" instead.
+func jsonFieldValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	default:
+		if _, err := json.Marshal(v); err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return v
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/testutil"
+)
+
+type jsonError struct {
+	Message string
+	Code    string
+	Fields  map[string]interface{}
+	Stack   []errors.Frame
+	Cause   *jsonError
+	Causes  []*jsonError
+}
+
+func TestMarshalJSON(t *testing.T) {
+	err := errors.Wrap(
+		errors.WithCode(errors.WithFields(errors.New(msg), "tenant", "a", "attempt", 2), errors.NotFound),
+		wrapper,
+	)
+
+	b, jerr := json.Marshal(err)
+	testutil.Ok(t, jerr)
+
+	var got jsonError
+	testutil.Ok(t, json.Unmarshal(b, &got))
+	testutil.Equals(t, wrapper, got.Message)
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.TestMarshalJSON", got.Stack[0].Function)
+	testutil.Assert(t, strings.HasSuffix(got.Stack[0].File, "/errors/json_test.go"))
+
+	testutil.Equals(t, "NotFound", got.Cause.Code)
+	testutil.Equals(t, map[string]interface{}{"tenant": "a", "attempt": float64(2)}, got.Cause.Cause.Fields)
+
+	root := got.Cause.Cause.Cause
+	testutil.Equals(t, msg, root.Message)
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.TestMarshalJSON", root.Stack[0].Function)
+	testutil.Assert(t, root.Cause == nil)
+}
+
+func TestMarshalJSON_MultiCause(t *testing.T) {
+	err := errors.Wrap(merrors.New(errors.New("a"), fmt.Errorf("std %w", errors.New("b"))).Err(), wrapper)
+
+	b, jerr := json.Marshal(err)
+	testutil.Ok(t, jerr)
+
+	var got jsonError
+	testutil.Ok(t, json.Unmarshal(b, &got))
+	testutil.Equals(t, wrapper, got.Message)
+
+	multi := got.Cause
+	testutil.Equals(t, "2 errors: a; std b", multi.Message)
+	testutil.Equals(t, 2, len(multi.Causes))
+	testutil.Equals(t, "a", multi.Causes[0].Message)
+	testutil.Assert(t, len(multi.Causes[0].Stack) > 0)
+	testutil.Equals(t, "std b", multi.Causes[1].Message)
+}
+
+func TestMarshalJSON_UnsupportedFieldValue(t *testing.T) {
+	ch := make(chan int)
+	err := errors.WithFields(errors.New(msg), "ch", ch, "tenant", "a")
+
+	b, jerr := json.Marshal(err)
+	testutil.Ok(t, jerr)
+
+	var got jsonError
+	testutil.Ok(t, json.Unmarshal(b, &got))
+	testutil.Equals(t, map[string]interface{}{"ch": fmt.Sprintf("%v", ch), "tenant": "a"}, got.Fields)
+	testutil.Equals(t, msg, got.Cause.Message)
+	testutil.Assert(t, len(got.Cause.Stack) > 0)
+
+	// Encoding keeps the whole chain too, instead of falling back to the message only.
+	decoded := errors.Decode(errors.Encode(err))
+	testutil.Equals(t, err.Error(), decoded.Error())
+	testutil.Equals(t, []interface{}{"ch", fmt.Sprintf("%v", ch), "tenant", "a"}, errors.Fields(decoded))
+	testutil.Equals(t, errors.StackTrace(err), errors.StackTrace(decoded))
+}
@@ -0,0 +1,22 @@
+module github.com/efficientgo/core/errors/otelerrors
+
+go 1.18
+
+require (
+	github.com/efficientgo/core v1.1.0
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/go-cmp v0.5.9 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
+
+// Use core from this repository for local development. It is ignored when the module is used as a dependency,
+// so the required core version has to contain all the APIs used by this module.
+replace github.com/efficientgo/core => ../..
@@ -0,0 +1,92 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+// Package otelerrors records errors created by github.com/efficientgo/core/errors on OpenTelemetry spans,
+// keeping the error chain and stacktraces. It is a separate module, so the errors package does not depend
+// on OpenTelemetry.
+package otelerrors
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/efficientgo/core/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordError records err on span as exception events with exception.type, exception.message and
+// exception.stacktrace attributes. Contrary to span.RecordError, one event is recorded for each error
+// in the chain, which has a stacktrace (i.e. was created with New, Wrap and similar functions) and for each
+// error not wrapping other errors, so for each member of multi errors (e.g. from merrors) too.
+// Events are recorded from the outermost error. It does nothing if err is nil or span is not recording.
+//
+// It does not change the span status, use span.SetStatus for this.
+func RecordError(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	errors.Walk(err, func(err error, _ int) bool {
+		st, hasFrames := err.(interface{ Frames() []errors.Frame })
+		if !hasFrames && wrapsErrors(err) {
+			// Annotations (e.g. errors.WithFields) or wrappers without stacktrace, their wrapped errors are recorded.
+			return true
+		}
+
+		var stacktrace string
+		if hasFrames {
+			stacktrace = formatFrames(st.Frames())
+		}
+		eventOpts := append([]trace.EventOption{trace.WithAttributes(
+			semconv.ExceptionType(typeName(err)),
+			semconv.ExceptionMessage(err.Error()),
+			semconv.ExceptionStacktrace(stacktrace),
+		)}, opts...)
+		span.AddEvent(semconv.ExceptionEventName, eventOpts...)
+		return true
+	})
+}
+
+// wrapsErrors returns true if err wraps at least one error.
+func wrapsErrors(err error) bool {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap() != nil
+	case interface{ Unwrap() []error }:
+		return len(e.Unwrap()) > 0
+	}
+	return false
+}
+
+// typeName returns type of err with the full package path, e.g. "*github.com/efficientgo/core/errors.base".
+func typeName(err error) string {
+	t := reflect.TypeOf(err)
+	prefix := ""
+	if t.Kind() == reflect.Ptr {
+		prefix, t = "*", t.Elem()
+	}
+	if t.PkgPath() == "" || t.Name() == "" {
+		// Likely a builtin or unnamed type.
+		return reflect.TypeOf(err).String()
+	}
+	return prefix + t.PkgPath() + "." + t.Name()
+}
+
+// formatFrames formats frames the same as Go runtime does in panics, e.g.:
+//
+//	main.main()
+//		/home/user/main.go:12
+func formatFrames(frames []errors.Frame) string {
+	var buf strings.Builder
+	for _, f := range frames {
+		buf.WriteString(f.Function)
+		buf.WriteString("()\n\t")
+		buf.WriteString(f.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(f.Line))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
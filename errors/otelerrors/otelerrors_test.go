@@ -0,0 +1,65 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package otelerrors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/errors/otelerrors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+type customErr struct{}
+
+func (customErr) Error() string { return "custom" }
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	err := errors.Wrap(errors.WithFields(merrors.New(
+		errors.New("a"),
+		customErr{},
+	).Err(), "tenant", "x"), "b")
+
+	_, span := tracer.Start(context.Background(), "op")
+	otelerrors.RecordError(span, err)
+	otelerrors.RecordError(span, nil)
+	span.End()
+
+	spans := recorder.Ended()
+	testutil.Equals(t, 1, len(spans))
+
+	events := spans[0].Events()
+	testutil.Equals(t, 3, len(events))
+
+	type exception struct{ typ, msg, stacktrace string }
+	var got []exception
+	for _, e := range events {
+		testutil.Equals(t, semconv.ExceptionEventName, e.Name)
+		attrs := attribute.NewSet(e.Attributes...)
+		typ, _ := attrs.Value(semconv.ExceptionTypeKey)
+		msg, _ := attrs.Value(semconv.ExceptionMessageKey)
+		st, _ := attrs.Value(semconv.ExceptionStacktraceKey)
+		got = append(got, exception{typ: typ.AsString(), msg: msg.AsString(), stacktrace: st.AsString()})
+	}
+
+	testutil.Equals(t, "*github.com/efficientgo/core/errors.base", got[0].typ)
+	testutil.Equals(t, "b: 2 errors: a; custom", got[0].msg)
+	testutil.Assert(t, strings.HasPrefix(got[0].stacktrace, "github.com/efficientgo/core/errors/otelerrors_test.TestRecordError()\n\t"), got[0].stacktrace)
+
+	testutil.Equals(t, "*github.com/efficientgo/core/errors.base", got[1].typ)
+	testutil.Equals(t, "a", got[1].msg)
+	testutil.Assert(t, strings.Contains(got[1].stacktrace, "otelerrors_test.go:"), got[1].stacktrace)
+
+	testutil.Equals(t, exception{typ: "github.com/efficientgo/core/errors/otelerrors_test.customErr", msg: "custom"}, got[2])
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// Recover recovers from panic (if any) and sets err to the error returned by FromPanic. It has to be deferred directly:
+//
+//	func do() (err error) {
+//		defer errors.Recover(&err)
+//		// ...
+//	}
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = fromPanic(r)
+	}
+}
+
+// FromPanic returns error for the value recovered from panic. If called in a deferred function while panicking, the
+// stacktrace of the error starts at the panic point instead of the deferred function. If r is an error, it is wrapped,
+// so errors.Is and errors.As can match it. It returns nil if r is nil.
+func FromPanic(r interface{}) error {
+	if r == nil {
+		return nil
+	}
+	return fromPanic(r)
+}
+
+func fromPanic(r interface{}) error {
+	b := &base{info: "panic", stack: newPanicStackTrace()}
+	if err, ok := r.(error); ok {
+		b.err = err
+	} else {
+		b.info = fmt.Sprintf("panic: %v", r)
+	}
+	runPanicHooks(b)
+	return b
+}
+
+// newPanicStackTrace captures a stack trace starting from the function that panicked, if called while panicking.
+// Otherwise, it captures a stack trace the same way as newStackTrace does.
+func newPanicStackTrace() stacktrace {
+	depth := int(atomic.LoadInt32(&stackDepth))
+	if depth == 0 {
+		return nil
+	}
+
+	// Record more frames, as the frames between the panic point and deferred function will be skipped.
+	pc := make([]uintptr, depth+32)
+	// Skip runtime.Callers, newPanicStackTrace, fromPanic and FromPanic or Recover.
+	pc = pc[:runtime.Callers(4, pc)]
+	for i := range pc {
+		if funcName(pc[i]) != "runtime.gopanic" {
+			continue
+		}
+		// Skip runtime functions between gopanic and the panicking function (e.g. runtime.sigpanic).
+		i++
+		for i < len(pc) && strings.HasPrefix(funcName(pc[i]), "runtime.") {
+			i++
+		}
+		pc = pc[i:]
+		break
+	}
+	if len(pc) > depth {
+		pc = pc[:depth]
+	}
+
+	// Copy to not retain the bigger underlying array.
+	st := make(stacktrace, len(pc))
+	copy(st, pc)
+	return st
+}
+
+func funcName(pc uintptr) string {
+	// Program counters returned by runtime.Callers are return addresses, so look up the call instruction.
+	if f := runtime.FuncForPC(pc - 1); f != nil {
+		return f.Name()
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func panicWith(r interface{}) {
+	panic(r)
+}
+
+func indexOutOfRange(i int) int {
+	return []int{1}[i]
+}
+
+func TestRecover(t *testing.T) {
+	testutil.Ok(t, errors.FromPanic(nil))
+
+	for _, tc := range []struct {
+		do                    func()
+		expectedMsg           string
+		expectedPanicFunction string
+	}{
+		{
+			do:                    func() { panicWith("something bad") },
+			expectedMsg:           "panic: something bad",
+			expectedPanicFunction: "github.com/efficientgo/core/errors_test.panicWith",
+		},
+		{
+			do:                    func() { panicWith(errors.Wrap(io.EOF, "reading")) },
+			expectedMsg:           "panic: reading: EOF",
+			expectedPanicFunction: "github.com/efficientgo/core/errors_test.panicWith",
+		},
+		{
+			do:                    func() { indexOutOfRange(2) },
+			expectedMsg:           "panic: runtime error: index out of range [2] with length 1",
+			expectedPanicFunction: "github.com/efficientgo/core/errors_test.indexOutOfRange",
+		},
+	} {
+		t.Run(tc.expectedMsg, func(t *testing.T) {
+			err := func() (err error) {
+				defer errors.Recover(&err)
+				tc.do()
+				return nil
+			}()
+			testutil.NotOk(t, err)
+			testutil.Equals(t, tc.expectedMsg, err.Error())
+
+			// Outermost frames point to the panic point.
+			frames := err.(interface{ Frames() []errors.Frame }).Frames()
+			testutil.Equals(t, tc.expectedPanicFunction, frames[0].Function)
+		})
+	}
+
+	err := func() (err error) {
+		defer errors.Recover(&err)
+		panicWith(io.EOF)
+		return nil
+	}()
+	testutil.Assert(t, errors.Is(err, io.EOF))
+
+	var rerr runtime.Error
+	err = func() (err error) {
+		defer func() {
+			err = errors.FromPanic(recover())
+		}()
+		indexOutOfRange(3)
+		return nil
+	}()
+	testutil.Assert(t, errors.As(err, &rerr))
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.indexOutOfRange", errors.StackTrace(err)[0].Function)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RedactedMarker replaces sensitive parts of error messages printed by Redacted.
+const RedactedMarker = "[REDACTED]"
+
+// safeArg is a Newf or Wrapf argument marked with Safe.
+type safeArg struct {
+	v interface{}
+}
+
+// Format implements the fmt.Formatter interface, formatting the value as if it was passed directly.
+func (a safeArg) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, formatDirective(s, verb), a.v)
+}
+
+// unsafeArg is a Newf or Wrapf argument marked with Unsafe.
+type unsafeArg struct {
+	v interface{}
+}
+
+// Format implements the fmt.Formatter interface, formatting the value as if it was passed directly.
+func (a unsafeArg) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, formatDirective(s, verb), a.v)
+}
+
+// Safe marks Newf or Wrapf argument as safe to be printed by Redacted, e.g. when it does not contain any user data.
+// It does not change how the argument is printed in the error message, for any verb.
+func Safe(v interface{}) interface{} {
+	return safeArg{v: v}
+}
+
+// Unsafe marks Newf or Wrapf argument as sensitive, so it is replaced with RedactedMarker by Redacted.
+// All arguments not marked with Safe are treated as sensitive already, so Unsafe only documents the intent.
+// It does not change how the argument is printed in the error message, for any verb.
+func Unsafe(v interface{}) interface{} {
+	return unsafeArg{v: v}
+}
+
+// unmarkArgs returns args with values marked with Safe or Unsafe replaced with the values themselves, so verbs
+// handled by fmt before calling Format (%T and %p) and %w print and wrap the original values.
+func unmarkArgs(args []interface{}) []interface{} {
+	var unmarked []interface{}
+	for i, a := range args {
+		var v interface{}
+		switch t := a.(type) {
+		case safeArg:
+			v = t.v
+		case unsafeArg:
+			v = t.v
+		default:
+			if unmarked != nil {
+				unmarked[i] = a
+			}
+			continue
+		}
+		if unmarked == nil {
+			unmarked = make([]interface{}, len(args))
+			copy(unmarked, args[:i])
+		}
+		unmarked[i] = v
+	}
+	if unmarked == nil {
+		return args
+	}
+	return unmarked
+}
+
+// Redacted returns the error message with sensitive parts replaced with RedactedMarker, so it can be
+// printed in places where user data is not allowed (e.g. logs or support tickets). Error() is not affected.
+//
+// Messages passed to New and Wrap, as well as format strings and arguments marked with Safe passed to Newf and Wrapf
+// are printed. Other arguments are replaced, except errors, which are printed with Redacted too. Errors not created by
+// this package are replaced entirely, unless they are Sentinel errors or are registered with Register.
+func Redacted(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	switch e := err.(type) {
+	case *base:
+		if e.stackOnly {
+			return Redacted(e.err)
+		}
+		info := e.redactedInfo()
+		if e.err == nil {
+			return info
+		}
+		return info + ": " + Redacted(e.err)
+	case *annotated:
+		return Redacted(e.err)
+	case Sentinel:
+		// Sentinels are constants, so they do not contain user data.
+		return e.Error()
+	}
+
+	registry.mtx.RLock()
+	_, ok := registeredName(err)
+	registry.mtx.RUnlock()
+	if ok {
+		return err.Error()
+	}
+	return RedactedMarker
+}
+
+// redactedArgs returns Newf or Wrapf args needed to print the message with Redacted: values marked with Safe, errors
+// and integers (which can be used as "*" width or precision). Other args are replaced with nil, so they are not kept
+// alive by the error.
+func redactedArgs(args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	kept := make([]interface{}, len(args))
+	for i, a := range args {
+		switch a.(type) {
+		case safeArg, error, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+			kept[i] = a
+		}
+	}
+	return kept
+}
+
+// redactedInfo returns the error message with arguments not marked with Safe replaced.
+func (b *base) redactedInfo() string {
+	if len(b.args) == 0 {
+		return b.info
+	}
+
+	args := make([]interface{}, len(b.args))
+	for i, a := range b.args {
+		switch t := a.(type) {
+		case safeArg:
+			args[i] = t.v
+		case error:
+			args[i] = redactedArg(Redacted(t))
+		default:
+			args[i] = redactedArg(RedactedMarker)
+		}
+	}
+
+	f := []byte(b.format)
+	for _, v := range formatVerbs(b.format) {
+		if v.arg < 0 || v.arg >= len(args) {
+			continue
+		}
+		_, safe := b.args[v.arg].(safeArg)
+		switch f[v.pos] {
+		case '*':
+			// Width and precision do not contain user data, so they are used as they are.
+			if !safe {
+				args[v.arg] = b.args[v.arg]
+			}
+		case 'w':
+			// Errors passed with %w are formatted with the redacted formatter below.
+			f[v.pos] = 'v'
+		case 'T', 'p':
+			// Those are printed by fmt without calling Format, so they would print redactedArg instead of the marker.
+			if !safe {
+				f[v.pos] = 'v'
+			}
+		}
+	}
+	return fmt.Sprintf(string(f), args...)
+}
+
+// redactedArg is printed as the given string for any verb.
+type redactedArg string
+
+// Format implements the fmt.Formatter interface.
+func (a redactedArg) Format(s fmt.State, _ rune) {
+	_, _ = s.Write([]byte(a))
+}
+
+// formatDirective reconstructs the formatting directive (e.g. "%+5.2f") from the fmt.State and verb.
+func formatDirective(s fmt.State, verb rune) string {
+	var buf strings.Builder
+	buf.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			buf.WriteRune(flag)
+		}
+	}
+	if w, ok := s.Width(); ok {
+		buf.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := s.Precision(); ok {
+		buf.WriteByte('.')
+		buf.WriteString(strconv.Itoa(p))
+	}
+	buf.WriteRune(verb)
+	return buf.String()
+}
@@ -0,0 +1,98 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func ExampleRedacted() {
+	err := errors.Newf("object %s not found in bucket %s", errors.Unsafe("users/alice@example.com"), errors.Safe("prod"))
+	err = errors.Wrapf(err, "upload for user %v", "alice@example.com")
+
+	fmt.Println(err)
+	fmt.Println(errors.Redacted(err))
+
+	// Output: upload for user alice@example.com: object users/alice@example.com not found in bucket prod
+	// upload for user [REDACTED]: object [REDACTED] not found in bucket prod
+}
+
+var (
+	errRedactSentinel          = fmt.Errorf("sentinel")
+	registerRedactSentinelOnce sync.Once
+
+	redactPtr = new(int)
+)
+
+func TestRedacted(t *testing.T) {
+	// Registered sentinel errors are safe.
+	registerRedactSentinelOnce.Do(func() { errors.Register("errors_test.RedactSentinel", errRedactSentinel) })
+
+	for _, tc := range []struct {
+		err              error
+		expectedMsg      string
+		expectedRedacted string
+	}{
+		{err: nil},
+		{
+			err:              errors.New("not found"),
+			expectedMsg:      "not found",
+			expectedRedacted: "not found",
+		},
+		{
+			err:              errors.Newf("100%% of %5.1f%% in %q", errors.Safe(99.5), "bucket"),
+			expectedMsg:      "100% of  99.5% in \"bucket\"",
+			expectedRedacted: "100% of  99.5% in [REDACTED]",
+		},
+		{
+			err:              errors.Wrap(fmt.Errorf("std %s", "secret"), "reading"),
+			expectedMsg:      "reading: std secret",
+			expectedRedacted: "reading: [REDACTED]",
+		},
+		{
+			err:              errors.WithFields(errors.Wrapf(errRedactSentinel, "reading %v", errors.Safe(1)), "user", "secret"),
+			expectedMsg:      "reading 1: sentinel",
+			expectedRedacted: "reading 1: sentinel",
+		},
+		{
+			// Width and precision passed as arguments are kept.
+			err:              errors.Newf("a %*d %.*[4]f", 5, 3, errors.Safe(2), errors.Safe(1.234)),
+			expectedMsg:      "a     3 1.23",
+			expectedRedacted: "a [REDACTED] 1.23",
+		},
+		{
+			// Marking does not change the printed type.
+			err:              errors.Newf("got %T, want %T", errors.Safe(1), errors.Unsafe("a")),
+			expectedMsg:      "got int, want string",
+			expectedRedacted: "got int, want [REDACTED]",
+		},
+		{
+			err:              errors.Newf("%[2]T %[1]T %[1]d %[3]p", errors.Safe(1), "a", errors.Unsafe(redactPtr)),
+			expectedMsg:      fmt.Sprintf("string int 1 %p", redactPtr),
+			expectedRedacted: "[REDACTED] int 1 [REDACTED]",
+		},
+		{
+			err:              errors.Newf("uploading: %w", errors.Safe(errRedactSentinel)),
+			expectedMsg:      "uploading: sentinel",
+			expectedRedacted: "uploading: sentinel",
+		},
+		{
+			err:              errors.Newf("uploading %s: %w", "secret", errors.Newf("user %d, key %s", errors.Safe(1), "secret")),
+			expectedMsg:      "uploading secret: user 1, key secret",
+			expectedRedacted: "uploading [REDACTED]: user 1, key [REDACTED]",
+		},
+	} {
+		t.Run(tc.expectedMsg, func(t *testing.T) {
+			if tc.err != nil {
+				testutil.Equals(t, tc.expectedMsg, tc.err.Error())
+			}
+			testutil.Equals(t, tc.expectedRedacted, errors.Redacted(tc.err))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors
+
+// Sentinel is an error without a stacktrace, meant for package-level errors used as comparison targets of Is.
+// Contrary to New, it does not record the meaningless stacktrace of package initialization and can be declared
+// as constant, e.g.:
+//
+//	const ErrNotFound = errors.Sentinel("not found")
+//
+// Sentinels with the same message are equal, even if declared in different packages, so include enough context
+// in the message. Use WithStack (or Wrap) when returning sentinel, so the stacktrace is recorded where it is returned:
+//
+//	return errors.WithStack(ErrNotFound)
+type Sentinel string
+
+// Error implements the error interface.
+func (s Sentinel) Error() string {
+	return string(s)
+}
+
+// WithStack returns a new error, which wraps another error with a stacktrace containing recent call frames,
+// without changing its message. It is meant for returning sentinel errors (see Sentinel) or errors from other
+// packages, when there is nothing to add to the message.
+//
+// If cause is nil, it returns nil, similar to Wrap.
+func WithStack(cause error) error {
+	if cause == nil {
+		return nil
+	}
+
+	b := &base{
+		stack:     newStackTrace(),
+		err:       cause,
+		stackOnly: true,
+	}
+	runHooks(b)
+	return b
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+const errSentinelNotFound = errors.Sentinel("sentinel: not found")
+
+func findItem() error {
+	return errors.WithStack(errSentinelNotFound)
+}
+
+func ExampleSentinel() {
+	err := errors.Wrap(findItem(), "loading config")
+
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, errSentinelNotFound))
+
+	// Output: loading config: sentinel: not found
+	// true
+}
+
+func TestSentinel(t *testing.T) {
+	testutil.Ok(t, errors.WithStack(nil))
+	testutil.Equals(t, "sentinel: not found", fmt.Sprintf("%+v", errSentinelNotFound))
+
+	err := findItem()
+	testutil.Equals(t, "sentinel: not found", err.Error())
+	testutil.Assert(t, errors.Is(err, errSentinelNotFound))
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.findItem", errors.StackTrace(err)[0].Function)
+
+	out := fmt.Sprintf("%+v", errors.Wrap(err, wrapper))
+	testutil.Assert(t, strings.HasPrefix(out, wrapper+"\n> github.com/efficientgo/core/errors_test.TestSentinel\t"), out)
+	testutil.Assert(t, strings.Contains(out, "\nsentinel: not found\n> github.com/efficientgo/core/errors_test.findItem\t"), out)
+	testutil.Equals(t, 1, strings.Count(out, "sentinel: not found"), out)
+
+	testutil.Equals(t, "sentinel: not found", errors.Redacted(err))
+	testutil.Equals(t, errors.Fingerprint(findItem()), errors.Fingerprint(err))
+
+	decoded := errors.Decode(errors.Encode(err))
+	testutil.Equals(t, err.Error(), decoded.Error())
+	testutil.Assert(t, errors.Is(decoded, errSentinelNotFound))
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.findItem", errors.StackTrace(decoded)[0].Function)
+}
+
+func TestWithStack_Format(t *testing.T) {
+	for _, tc := range []struct {
+		err              error
+		expectedMessages []string
+		expectedStacks   int
+	}{
+		{
+			// Message of the wrapped error is printed once, followed by its cause.
+			err:              errors.WithStack(fmt.Errorf("x: %w", io.EOF)),
+			expectedMessages: []string{"x: EOF", "EOF"},
+			expectedStacks:   1,
+		},
+		{
+			err:              errors.WithStack(errors.WithCode(fmt.Errorf("x: %w", io.EOF), errors.NotFound)),
+			expectedMessages: []string{"x: EOF", "code: NotFound", "EOF"},
+			expectedStacks:   1,
+		},
+		{
+			// Stack of the wrapped error is printed too, under the same message.
+			err:              errors.WithStack(errors.WithStack(errors.Wrap(io.EOF, "x"))),
+			expectedMessages: []string{"x: EOF", "EOF"},
+			expectedStacks:   3,
+		},
+	} {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			out := errors.Formatter{FullStacks: true}.Format(tc.err)
+
+			var messages []string
+			for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+				if !strings.HasPrefix(line, "> ") {
+					messages = append(messages, line)
+				}
+			}
+			testutil.Equals(t, tc.expectedMessages, messages, out)
+			testutil.Equals(t, tc.expectedStacks, strings.Count(out, "> github.com/efficientgo/core/errors_test.TestWithStack_Format\t"), out)
+		})
+	}
+}
+
+func TestWithStack_Hook(t *testing.T) {
+	var calls []hookCall
+	defer errors.RegisterHook(func(message string, caller errors.Frame, cause error) {
+		calls = append(calls, hookCall{message: message, caller: caller, cause: cause})
+	})()
+
+	_ = errors.WithStack(io.EOF)
+	testutil.Equals(t, 1, len(calls))
+	// WithStack does not add anything to the message.
+	testutil.Equals(t, "", calls[0].message)
+	testutil.Assert(t, calls[0].cause == io.EOF)
+	testutil.Equals(t, "github.com/efficientgo/core/errors_test.TestWithStack_Hook", calls[0].caller.Function)
+}
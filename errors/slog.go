@@ -0,0 +1,39 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+//go:build go1.21
+
+package errors
+
+import "log/slog"
+
+// LogValue implements the slog.LogValuer interface. It renders the error as a group with the error message,
+// fields attached with WithFields (if any) and the stacktrace of the whole error chain (as printed with "%+v"),
+// so structured handlers can keep them apart.
+func (b *base) LogValue() slog.Value {
+	return logValue(b)
+}
+
+// LogValue implements the slog.LogValuer interface. See base.LogValue for details.
+func (a *annotated) LogValue() slog.Value {
+	return logValue(a)
+}
+
+// LogValue implements the slog.LogValuer interface. See base.LogValue for details.
+func (o *opaque) LogValue() slog.Value {
+	return logValue(o)
+}
+
+// LogValue implements the slog.LogValuer interface. See base.LogValue for details.
+func (o *opaqueMulti) LogValue() slog.Value {
+	return logValue(o)
+}
+
+func logValue(err error) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", err.Error())}
+	if fields := Fields(err); len(fields) > 0 {
+		attrs = append(attrs, slog.Group("fields", fields...))
+	}
+	attrs = append(attrs, slog.String("stacktrace", formatErrorChain(err)))
+	return slog.GroupValue(attrs...)
+}
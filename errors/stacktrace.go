@@ -9,21 +9,54 @@
 package errors
 
 import (
-	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// DefaultStackDepth is the default maximum number of call frames recorded for each error.
+const DefaultStackDepth = 16
+
+// stackDepth is the maximum number of call frames recorded for each error. Accessed atomically.
+var stackDepth int32 = DefaultStackDepth
+
+// SetStackDepth sets the maximum number of call frames recorded by errors created with New, Newf, Wrap and Wrapf.
+// Use 1 to record only the caller frame or 0 to disable stacktrace capture entirely, e.g. when expected
+// errors are created frequently on hot paths. Negative depth is treated as 0.
+//
+// It is safe to call it concurrently with error creation. It affects only errors created afterwards.
+func SetStackDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	atomic.StoreInt32(&stackDepth, int32(depth))
+}
+
+// Frame represents a single call frame of the stacktrace.
+type Frame struct {
+	// Function is the package path-qualified function name, e.g. "github.com/efficientgo/core/errors.New".
+	Function string `json:"function"`
+	// File is the absolute path of the file containing the function.
+	File string `json:"file"`
+	// Line is the line number in the file.
+	Line int `json:"line"`
+}
+
 // stacktrace holds a snapshot of program counters.
 type stacktrace []uintptr
 
 // newStackTrace captures a stack trace. It skips first 3 frames to record the
 // snapshot of the stack trace at the origin of a particular error. It tries to
-// record maximum 16 frames (if available).
+// record maximum number of frames set by SetStackDepth (if available).
 func newStackTrace() stacktrace {
-	const stackDepth = 16 // record maximum 16 frames (if available).
+	depth := atomic.LoadInt32(&stackDepth)
+	if depth == 0 {
+		return nil
+	}
 
-	pc := make([]uintptr, stackDepth)
+	pc := make([]uintptr, depth)
 	// using skip=3 for not to count the program counter address of
 	// 1. the respective function from errors package (eg. errors.New)
 	// 2. newStacktrace itself
@@ -37,22 +70,98 @@ func newStackTrace() stacktrace {
 	return pc[:n:n]
 }
 
-// String implements the fmt.Stringer interface to provide formatted text output.
-func (s stacktrace) String() string {
-	var buf strings.Builder
+// maxCachedFrames is the maximum number of program counters with symbolized frames kept in frameCache.
+const maxCachedFrames = 4096
+
+// frameCache holds symbolized frames for program counters, as errors created at the same places are often
+// formatted repeatedly (e.g. logged). When the cache is full, it is cleared, so it never grows beyond maxCachedFrames.
+var frameCache = struct {
+	mtx sync.RWMutex
+	// frames maps program counter to frames, which can be more than one if functions were inlined.
+	frames map[uintptr][]Frame
+}{frames: map[uintptr][]Frame{}}
+
+// frames returns the symbolized call frames of the stacktrace, starting from the most recent call.
+func (s stacktrace) frames() []Frame {
+	if len(s) == 0 {
+		return nil
+	}
 
+	frames := make([]Frame, 0, len(s))
+	cached := true
+
+	frameCache.mtx.RLock()
+	for _, pc := range s {
+		f, ok := frameCache.frames[pc]
+		if !ok {
+			cached = false
+			break
+		}
+		frames = append(frames, f...)
+	}
+	frameCache.mtx.RUnlock()
+
+	if cached {
+		return frames
+	}
+
+	// Some frames are not cached, symbolize the whole stacktrace again, so order is kept without additional bookkeeping.
+	frames = frames[:0]
+	symbolized := make(map[uintptr][]Frame, len(s))
+	for _, pc := range s {
+		f, ok := symbolized[pc]
+		if !ok {
+			f = symbolize(pc)
+			symbolized[pc] = f
+		}
+		frames = append(frames, f...)
+	}
+
+	frameCache.mtx.Lock()
+	if len(frameCache.frames)+len(symbolized) > maxCachedFrames {
+		frameCache.frames = make(map[uintptr][]Frame, len(symbolized))
+	}
+	for pc, f := range symbolized {
+		frameCache.frames[pc] = f
+	}
+	frameCache.mtx.Unlock()
+	return frames
+}
+
+// symbolize returns call frames of the program counter, which can be more than one if functions were inlined.
+func symbolize(pc uintptr) []Frame {
+	var frames []Frame
 	// CallersFrames takes the slice of Program Counter addresses returned by Callers to
 	// retrieve function/file/line information.
-	cf := runtime.CallersFrames(s)
+	cf := runtime.CallersFrames([]uintptr{pc})
 	for {
 		// more indicates if the next call will be successful or not.
 		frame, more := cf.Next()
-		// used formatting scheme <`>`space><function name><tab><filepath><:><line><newline> for example:
-		// > testing.tRunner	/home/go/go1.17.8/src/testing/testing.go:1259
-		buf.WriteString(fmt.Sprintf("> %s\t%s:%d\n", frame.Func.Name(), frame.File, frame.Line))
+		frames = append(frames, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
 		if !more {
 			break
 		}
 	}
+	return frames
+}
+
+// String implements the fmt.Stringer interface to provide formatted text output.
+func (s stacktrace) String() string {
+	var buf strings.Builder
+	for _, f := range s.frames() {
+		writeFrame(&buf, f.Function, f.File, f.Line)
+		buf.WriteByte('\n')
+	}
 	return buf.String()
 }
+
+// writeFrame writes the frame using formatting scheme <`>`space><function name><tab><filepath><:><line>, for example:
+// > testing.tRunner	/home/go/go1.17.8/src/testing/testing.go:1259
+func writeFrame(buf *strings.Builder, function, file string, line int) {
+	buf.WriteString("> ")
+	buf.WriteString(function)
+	buf.WriteByte('\t')
+	buf.WriteString(file)
+	buf.WriteByte(':')
+	buf.WriteString(strconv.Itoa(line))
+}
@@ -9,6 +9,8 @@
 package errors
 
 import (
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -19,7 +21,7 @@ func caller() stacktrace {
 
 func TestStacktraceOutput(t *testing.T) {
 	st := caller()
-	expectedPhrase := "/errors/stacktrace_test.go:21"
+	expectedPhrase := "/errors/stacktrace_test.go:23"
 	if !strings.Contains(st.String(), expectedPhrase) {
 		t.Fatalf("expectedUnwrap %v phrase into the stacktrace, received stacktrace: \n%v", expectedPhrase, st.String())
 	}
@@ -33,3 +35,108 @@ func TestStacktraceProgramCounterLen(t *testing.T) {
 		t.Fatalf("output lines vs program counter size mismatch: program counter size %v, output lines %v", len(st), lines)
 	}
 }
+
+func TestSetStackDepth(t *testing.T) {
+	defer SetStackDepth(DefaultStackDepth)
+
+	for _, tc := range []struct {
+		depth         int
+		expectedDepth int
+	}{
+		{depth: 1, expectedDepth: 1},
+		{depth: 3, expectedDepth: 3},
+		{depth: 0, expectedDepth: 0},
+		{depth: -1, expectedDepth: 0},
+	} {
+		SetStackDepth(tc.depth)
+
+		err := New("error").(*base)
+		if len(err.stack) != tc.expectedDepth {
+			t.Fatalf("expected %v frames for depth %v, got %v", tc.expectedDepth, tc.depth, len(err.stack))
+		}
+		if tc.expectedDepth > 0 && err.Frames()[0].Function != "github.com/efficientgo/core/errors.TestSetStackDepth" {
+			t.Fatalf("expected caller frame first, got %v", err.Frames()[0])
+		}
+		if tc.expectedDepth == 0 && formatErrorChain(err) != "error\n" {
+			t.Fatalf("expected no stacktrace in output, got %v", formatErrorChain(err))
+		}
+	}
+}
+
+var (
+	benchErr error
+	benchOut string
+)
+
+func BenchmarkNew(b *testing.B) {
+	defer SetStackDepth(DefaultStackDepth)
+
+	for _, tc := range []struct {
+		name  string
+		depth int
+	}{
+		{name: "default", depth: DefaultStackDepth},
+		{name: "caller-only", depth: 1},
+		{name: "disabled", depth: 0},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			SetStackDepth(tc.depth)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				benchErr = Wrap(New("error"), "wrapped")
+			}
+		})
+	}
+}
+
+func TestStacktraceFrames_Cached(t *testing.T) {
+	st := caller()
+	expected := make([]Frame, 0, len(st))
+	cf := runtime.CallersFrames(st)
+	for {
+		f, more := cf.Next()
+		expected = append(expected, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+
+	// First call symbolizes and caches frames, second one uses the cache.
+	for i := 0; i < 2; i++ {
+		if got := st.frames(); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("expected frames %v, got %v", expected, got)
+		}
+	}
+	for _, pc := range st {
+		if _, ok := frameCache.frames[pc]; !ok {
+			t.Fatalf("expected frame for %v cached", pc)
+		}
+	}
+}
+
+func BenchmarkFormatErrorChain(b *testing.B) {
+	err := Wrap(Wrapf(Wrap(New("error"), "wrapped 1"), "wrapped %d", 2), "wrapped 3")
+
+	for _, tc := range []struct {
+		name   string
+		cached bool
+	}{
+		{name: "cold", cached: false},
+		{name: "cached", cached: true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !tc.cached {
+					frameCache.mtx.Lock()
+					frameCache.frames = map[uintptr][]Frame{}
+					frameCache.mtx.Unlock()
+				}
+				benchOut = formatErrorChain(err)
+			}
+		})
+	}
+}
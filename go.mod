@@ -1,6 +1,6 @@
 module github.com/efficientgo/core
 
-go 1.17
+go 1.18
 
 require (
 	github.com/davecgh/go-spew v1.1.1
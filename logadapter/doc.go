@@ -0,0 +1,18 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+// Package logadapter implements adapters from standard library loggers to the go-kit compatible Logger interface
+// used across this module (e.g. runutil.Logger and logerrcapture.Logger).
+//
+// For log/slog (Go 1.21+), use NewSlog. The "level" and "msg" keys are mapped to the record level and message,
+// and errors created with github.com/efficientgo/core/errors are logged as a group with the message and stacktrace:
+//
+//	logger := logadapter.NewSlog(slog.Default())
+//	defer logerrcapture.Do(logger, f.Close, "close file at the end")
+//
+// For log.Logger, use NewStd, which prints all key-values in logfmt-like format:
+//
+//	err := runutil.RetryWithLog(logadapter.NewStd(log.Default()), 10*time.Second, stopc, func() error {
+//		// ...
+//	})
+package logadapter
@@ -0,0 +1,94 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package logadapter
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/efficientgo/core/errors"
+)
+
+const (
+	levelKey = "level"
+	msgKey   = "msg"
+
+	// missingValue is used when odd number of key-values is passed, same as go-kit/log does.
+	missingValue = "(MISSING)"
+)
+
+// StdLogger adapts log.Logger to the go-kit compatible Logger interface.
+type StdLogger struct {
+	l *log.Logger
+}
+
+// NewStd returns StdLogger that prints each Log call as a single line of key=value pairs using given log.Logger.
+func NewStd(l *log.Logger) *StdLogger {
+	return &StdLogger{l: l}
+}
+
+// Log implements the go-kit compatible Logger interface.
+func (s *StdLogger) Log(keyvals ...interface{}) error {
+	var buf strings.Builder
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(keyString(keyvals[i]))
+		buf.WriteByte('=')
+		v := valueAt(keyvals, i+1)
+		buf.WriteString(quoteIfNeeded(valueString(v)))
+
+		// Print fields attached to errors as separate key-values prefixed with the error key.
+		if err, ok := v.(error); ok {
+			fields := errors.Fields(err)
+			for j := 0; j < len(fields); j += 2 {
+				buf.WriteByte(' ')
+				buf.WriteString(keyString(keyvals[i]) + "." + keyString(fields[j]))
+				buf.WriteByte('=')
+				buf.WriteString(quoteIfNeeded(valueString(fields[j+1])))
+			}
+		}
+	}
+	// Skip Output and Log itself, so the log.Lshortfile and log.Llongfile flags point to the Log caller.
+	return s.l.Output(2, buf.String())
+}
+
+func valueAt(keyvals []interface{}, i int) interface{} {
+	if i >= len(keyvals) {
+		return missingValue
+	}
+	return keyvals[i]
+}
+
+func keyString(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return valueString(k)
+}
+
+func valueString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
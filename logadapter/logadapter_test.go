@@ -0,0 +1,37 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package logadapter_test
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/logadapter"
+	"github.com/efficientgo/core/logerrcapture"
+	"github.com/efficientgo/core/runutil"
+	"github.com/efficientgo/core/testutil"
+)
+
+var (
+	_ runutil.Logger       = &logadapter.StdLogger{}
+	_ logerrcapture.Logger = &logadapter.StdLogger{}
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logadapter.NewStd(log.New(&buf, "", 0))
+
+	testutil.Ok(t, logger.Log("level", "warn", "msg", "function failed", "err", errors.New("some error"), "attempt", 2))
+	testutil.Equals(t, "level=warn msg=\"function failed\" err=\"some error\" attempt=2\n", buf.String())
+
+	buf.Reset()
+	testutil.Ok(t, logger.Log("err", errors.WithFields(errors.New("some error"), "tenant", "team a")))
+	testutil.Equals(t, "err=\"some error\" err.tenant=\"team a\"\n", buf.String())
+
+	buf.Reset()
+	testutil.Ok(t, logger.Log("msg", "", "odd"))
+	testutil.Equals(t, "msg=\"\" odd=(MISSING)\n", buf.String())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+//go:build go1.21
+
+package logadapter
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SlogLogger adapts slog.Logger to the go-kit compatible Logger interface.
+type SlogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlog returns SlogLogger that logs each Log call as a single slog record using given slog.Logger.
+//
+// The "level" key value (string like "debug", "info", "warn", "error", fmt.Stringer or slog.Level) becomes the record
+// level (info if not specified) and the "msg" key value becomes the record message. All other key-values are
+// passed as attributes. Errors from github.com/efficientgo/core/errors implement slog.LogValuer, so they are
+// logged as a group with the message, fields and stacktrace instead of a flattened string.
+func NewSlog(l *slog.Logger) *SlogLogger {
+	return &SlogLogger{l: l}
+}
+
+// Log implements the go-kit compatible Logger interface.
+func (s *SlogLogger) Log(keyvals ...interface{}) error {
+	var (
+		ctx   = context.Background()
+		level = slog.LevelInfo
+		msg   string
+		attrs = make([]slog.Attr, 0, (len(keyvals)+1)/2)
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		k, v := keyString(keyvals[i]), valueAt(keyvals, i+1)
+		switch k {
+		case levelKey:
+			level = slogLevel(v)
+			continue
+		case msgKey:
+			msg = valueString(v)
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, v))
+	}
+
+	if !s.l.Enabled(ctx, level) {
+		return nil
+	}
+
+	// Skip Callers and Log itself, so the source attribute points to the Log caller.
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	return s.l.Handler().Handle(ctx, r)
+}
+
+func slogLevel(v interface{}) slog.Level {
+	if l, ok := v.(slog.Level); ok {
+		return l
+	}
+
+	switch strings.ToLower(valueString(v)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
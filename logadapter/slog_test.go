@@ -0,0 +1,93 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+//go:build go1.21
+
+package logadapter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/logadapter"
+	"github.com/efficientgo/core/logerrcapture"
+	"github.com/efficientgo/core/runutil"
+	"github.com/efficientgo/core/testutil"
+)
+
+var (
+	_ runutil.Logger       = &logadapter.SlogLogger{}
+	_ logerrcapture.Logger = &logadapter.SlogLogger{}
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logadapter.NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	t.Run("errors package error", func(t *testing.T) {
+		buf.Reset()
+		testutil.Ok(t, logger.Log("level", "error", "msg", "function failed", "err", errors.Wrap(errors.WithFields(errors.New("root"), "tenant", "a"), "wrapped"), "attempt", 2))
+
+		var rec struct {
+			Level   string
+			Msg     string
+			Attempt int
+			Err     struct {
+				Msg        string
+				Fields     map[string]string
+				Stacktrace string
+			}
+		}
+		testutil.Ok(t, json.Unmarshal(buf.Bytes(), &rec))
+		testutil.Equals(t, "ERROR", rec.Level)
+		testutil.Equals(t, "function failed", rec.Msg)
+		testutil.Equals(t, 2, rec.Attempt)
+		testutil.Equals(t, "wrapped: root", rec.Err.Msg)
+		testutil.Equals(t, map[string]string{"tenant": "a"}, rec.Err.Fields)
+		testutil.Assert(t, strings.Contains(rec.Err.Stacktrace, "logadapter_test.TestSlogLogger"), "expected stacktrace, got %v", rec.Err.Stacktrace)
+	})
+	t.Run("errors package annotation as the outermost error", func(t *testing.T) {
+		for _, err := range []error{
+			errors.WithCode(errors.New("root"), errors.NotFound),
+			errors.WithHint(errors.New("root"), "hint"),
+			errors.WithDetail(errors.New("root"), "detail"),
+			errors.WithExitCode(errors.New("root"), 2),
+			// Decoded foreign error wrapping errors package error.
+			errors.Decode(errors.Encode(fmt.Errorf("wrapped: %w", errors.New("root")))),
+		} {
+			buf.Reset()
+			testutil.Ok(t, logger.Log("msg", "function failed", "err", err))
+
+			var rec struct {
+				Err struct {
+					Msg        string
+					Stacktrace string
+				}
+			}
+			testutil.Ok(t, json.Unmarshal(buf.Bytes(), &rec))
+			testutil.Equals(t, err.Error(), rec.Err.Msg)
+			testutil.Assert(t, strings.Contains(rec.Err.Stacktrace, "logadapter_test.TestSlogLogger"), "expected stacktrace, got %v", rec.Err.Stacktrace)
+		}
+	})
+	t.Run("no level and odd key-values", func(t *testing.T) {
+		buf.Reset()
+		testutil.Ok(t, logger.Log("msg", "hello", "odd"))
+
+		var rec map[string]interface{}
+		testutil.Ok(t, json.Unmarshal(buf.Bytes(), &rec))
+		testutil.Equals(t, "INFO", rec["level"])
+		testutil.Equals(t, "hello", rec["msg"])
+		testutil.Equals(t, "(MISSING)", rec["odd"])
+	})
+	t.Run("level filtered out", func(t *testing.T) {
+		buf.Reset()
+		logger := logadapter.NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+		testutil.Ok(t, logger.Log("level", "debug", "msg", "hello"))
+		testutil.Equals(t, 0, buf.Len())
+	})
+}
@@ -5,6 +5,7 @@ package merrors
 
 import (
 	"bytes"
+	"encoding/json"
 	stderrors "errors"
 	"fmt"
 	"io"
@@ -82,6 +83,12 @@ func (e multiError) Errors() []error {
 	return e.errs
 }
 
+// Unwrap returns underlying errors. It allows Go 1.20+ errors.Is and errors.As, as well as
+// github.com/efficientgo/core/errors formatting, to traverse each of them.
+func (e multiError) Unwrap() []error {
+	return e.errs
+}
+
 // Error returns a concatenated string of the contained errors.
 func (e multiError) Error() string {
 	var buf bytes.Buffer
@@ -99,6 +106,28 @@ func (e multiError) Error() string {
 	return buf.String()
 }
 
+// MarshalJSON implements the json.Marshaler interface. The multi error is marshaled as a JSON object with
+// "message" and "causes" list. Errors implementing json.Marshaler (e.g. from github.com/efficientgo/core/errors)
+// are marshaled by themselves, other errors as JSON objects with "message".
+func (e multiError) MarshalJSON() ([]byte, error) {
+	type jsonMessage struct {
+		Message string `json:"message"`
+	}
+
+	causes := make([]interface{}, 0, len(e.errs))
+	for _, err := range e.errs {
+		if _, ok := err.(json.Marshaler); ok {
+			causes = append(causes, err)
+			continue
+		}
+		causes = append(causes, jsonMessage{Message: err.Error()})
+	}
+	return json.Marshal(struct {
+		Message string        `json:"message"`
+		Causes  []interface{} `json:"causes"`
+	}{Message: e.Error(), Causes: causes})
+}
+
 // As finds the first error in multiError slice of error chains that matches target, and if so, sets
 // target to that error value and returns true. Otherwise, it returns false.
 //
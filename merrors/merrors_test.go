@@ -4,6 +4,7 @@
 package merrors_test
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -227,3 +228,25 @@ func TestAsMulti(t *testing.T) {
 	testutil.Assert(t, ok)
 	testutil.Assert(t, errors.Is(m, merr))
 }
+
+func TestMultiError_MarshalJSON(t *testing.T) {
+	err := merrors.New(errors.New("std"), corerrors.New("core")).Err()
+
+	b, jerr := json.Marshal(err)
+	testutil.Ok(t, jerr)
+
+	var got struct {
+		Message string
+		Causes  []struct {
+			Message string
+			Stack   []corerrors.Frame
+		}
+	}
+	testutil.Ok(t, json.Unmarshal(b, &got))
+	testutil.Equals(t, "2 errors: std; core", got.Message)
+	testutil.Equals(t, 2, len(got.Causes))
+	testutil.Equals(t, "std", got.Causes[0].Message)
+	testutil.Equals(t, 0, len(got.Causes[0].Stack))
+	testutil.Equals(t, "core", got.Causes[1].Message)
+	testutil.Equals(t, "github.com/efficientgo/core/merrors_test.TestMultiError_MarshalJSON", got.Causes[1].Stack[0].Function)
+}
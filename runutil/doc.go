@@ -13,6 +13,22 @@
 //		// ...
 //	})
 //
+// To keep repeating despite f errors, use RepeatWithLog, which logs each f error and backs off while f keeps failing:
+//
+//	err := runutil.RepeatWithLog(logger, 10*time.Second, stopc, func() error {
+//		// ...
+//	})
+//
+// To configure the backoff, use RepeatWithConfig:
+//
+//	err := runutil.RepeatWithConfig(runutil.RepeatConfig{
+//		Interval: 10 * time.Second,
+//		Backoff:  backoff.Config{Min: time.Second, Max: time.Minute},
+//		Logger:   logger,
+//	}, stopc, func() error {
+//		// ...
+//	})
+//
 // Retry starts executing closure function f until no error is returned from f:
 //
 //	err := runutil.Retry(10*time.Second, stopc, func() error {
@@ -24,4 +40,29 @@
 //	err := runutil.RetryWithLog(logger, 10*time.Second, stopc, func() error {
 //		// ...
 //	})
+//
+// For limiting the number of attempts or the time each attempt can take, use RetryWithConfig:
+//
+//	err := runutil.RetryWithConfig(runutil.RetryConfig{
+//		Interval:       10 * time.Second,
+//		MaxAttempts:    5,
+//		AttemptTimeout: 30 * time.Second,
+//	}, stopc, func(ctx context.Context) error {
+//		// ...
+//	})
+//
+// To make sure that functions for the same key (e.g. tenant) never run concurrently, use KeyedLock:
+//
+//	l := runutil.NewKeyedLock(runutil.ExclusiveQueueOne)
+//	res, err := l.RunExclusive(tenant, func() error {
+//		// ...
+//	})
+//
+// To expose the status of a periodic loop (e.g. in readiness probes), use PeriodicJob:
+//
+//	job := runutil.NewPeriodicJob(10*time.Second, func() error {
+//		// ...
+//	})
+//	healthy := job.HealthCheck(3, 2)
+//	err := job.Repeat(stopc)
 package runutil
@@ -0,0 +1,129 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package runutil
+
+import (
+	"sync"
+	"time"
+)
+
+// ExclusiveMode defines what RunExclusive does when a function for the same key is already running.
+type ExclusiveMode int
+
+const (
+	// ExclusiveWait waits until the running function (and all callers waiting before) finish, then runs f.
+	ExclusiveWait ExclusiveMode = iota
+	// ExclusiveSkip does not run f and returns immediately.
+	ExclusiveSkip
+	// ExclusiveQueueOne queues f to run right after the running function finishes. If another
+	// function is already queued, f is not run, and the caller waits for the queued run and gets its error instead.
+	ExclusiveQueueOne
+)
+
+// ExclusiveResult describes what happened in RunExclusive call.
+type ExclusiveResult struct {
+	// Ran is true if the f passed to RunExclusive was executed.
+	Ran bool
+	// Waited is how long the caller waited for other functions with the same key, before running f or returning.
+	Waited time.Duration
+}
+
+// KeyedLock ensures that at most one function runs concurrently for the same key. Per-key state is
+// removed as soon as there are no running or waiting callers for the key.
+type KeyedLock struct {
+	mode ExclusiveMode
+
+	mtx  sync.Mutex
+	keys map[string]*keyState
+}
+
+type keyState struct {
+	// sem is held by the running function.
+	sem chan struct{}
+	// refs is number of callers for the key, running or waiting.
+	refs int
+	// queued is the run queued in ExclusiveQueueOne mode, if any.
+	queued *queuedRun
+}
+
+type queuedRun struct {
+	done chan struct{}
+	err  error
+}
+
+// NewKeyedLock returns KeyedLock with the given mode.
+func NewKeyedLock(mode ExclusiveMode) *KeyedLock {
+	return &KeyedLock{mode: mode, keys: map[string]*keyState{}}
+}
+
+// RunExclusive runs f, unless another function with the same key is running, in which case it
+// behaves according to the KeyedLock mode. It returns f error, or the error of the queued run
+// if caller was coalesced in ExclusiveQueueOne mode.
+func (l *KeyedLock) RunExclusive(key string, f func() error) (ExclusiveResult, error) {
+	start := time.Now()
+
+	l.mtx.Lock()
+	st, ok := l.keys[key]
+	if !ok {
+		st = &keyState{sem: make(chan struct{}, 1)}
+		l.keys[key] = st
+	}
+	st.refs++
+	defer l.release(key, st)
+
+	// Nothing is queued ahead of us, so try to run immediately.
+	if st.queued == nil {
+		select {
+		case st.sem <- struct{}{}:
+			l.mtx.Unlock()
+			return ExclusiveResult{Ran: true, Waited: time.Since(start)}, l.run(st, f)
+		default:
+		}
+	}
+
+	switch l.mode {
+	case ExclusiveSkip:
+		l.mtx.Unlock()
+		return ExclusiveResult{Waited: time.Since(start)}, nil
+	case ExclusiveQueueOne:
+		if q := st.queued; q != nil {
+			l.mtx.Unlock()
+			<-q.done
+			return ExclusiveResult{Waited: time.Since(start)}, q.err
+		}
+		q := &queuedRun{done: make(chan struct{})}
+		st.queued = q
+		l.mtx.Unlock()
+
+		st.sem <- struct{}{}
+		l.mtx.Lock()
+		st.queued = nil
+		l.mtx.Unlock()
+
+		defer close(q.done)
+
+		res := ExclusiveResult{Ran: true, Waited: time.Since(start)}
+		q.err = l.run(st, f)
+		return res, q.err
+	default:
+		l.mtx.Unlock()
+		st.sem <- struct{}{}
+		return ExclusiveResult{Ran: true, Waited: time.Since(start)}, l.run(st, f)
+	}
+}
+
+func (l *KeyedLock) run(st *keyState, f func() error) error {
+	defer func() { <-st.sem }()
+	return f()
+}
+
+func (l *KeyedLock) release(key string, st *keyState) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	st.refs--
+	if st.refs == 0 {
+		delete(l.keys, key)
+	}
+}
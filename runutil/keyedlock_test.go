@@ -0,0 +1,133 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package runutil
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+type exclusiveRun struct {
+	res ExclusiveResult
+	err error
+}
+
+func TestKeyedLock(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mode ExclusiveMode
+	}{
+		{name: "wait", mode: ExclusiveWait},
+		{name: "skip", mode: ExclusiveSkip},
+		{name: "queue one", mode: ExclusiveQueueOne},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewKeyedLock(tc.mode)
+
+			var (
+				calls, running, overlaps int32
+				started                  = make(chan struct{})
+				unblock                  = make(chan struct{})
+			)
+			f := func() error {
+				if atomic.AddInt32(&running, 1) != 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				defer atomic.AddInt32(&running, -1)
+
+				call := atomic.AddInt32(&calls, 1)
+				if call == 1 {
+					close(started)
+					<-unblock
+				}
+				return errors.Newf("call %d failed", call)
+			}
+
+			first := make(chan exclusiveRun, 1)
+			go func() {
+				res, err := l.RunExclusive("tenant-1", f)
+				first <- exclusiveRun{res: res, err: err}
+			}()
+			<-started
+
+			others := make(chan exclusiveRun, 4)
+			for i := 0; i < 4; i++ {
+				go func() {
+					res, err := l.RunExclusive("tenant-1", f)
+					others <- exclusiveRun{res: res, err: err}
+				}()
+			}
+
+			// Other keys are not blocked.
+			res, err := l.RunExclusive("tenant-2", func() error { return nil })
+			testutil.Ok(t, err)
+			testutil.Assert(t, res.Ran)
+
+			var results []exclusiveRun
+			if tc.mode == ExclusiveSkip {
+				// Skipping callers return while the first one is still running.
+				for i := 0; i < 4; i++ {
+					results = append(results, <-others)
+				}
+			} else {
+				// Give other callers time to block, so the waited time is measurable.
+				time.Sleep(10 * time.Millisecond)
+			}
+			close(unblock)
+
+			r := <-first
+			testutil.Assert(t, r.res.Ran)
+			testutil.Equals(t, "call 1 failed", r.err.Error())
+			for len(results) < 4 {
+				results = append(results, <-others)
+			}
+			testutil.Equals(t, int32(0), atomic.LoadInt32(&overlaps), "functions for the same key overlapped")
+
+			ran := 1
+			runErrs := map[string]bool{}
+			var maxWaited time.Duration
+			for _, r := range results {
+				if r.res.Waited > maxWaited {
+					maxWaited = r.res.Waited
+				}
+				if r.res.Ran {
+					ran++
+					testutil.NotOk(t, r.err)
+					runErrs[r.err.Error()] = true
+				}
+			}
+			testutil.Equals(t, int32(ran), atomic.LoadInt32(&calls))
+
+			switch tc.mode {
+			case ExclusiveWait:
+				testutil.Equals(t, 5, ran)
+			case ExclusiveSkip:
+				testutil.Equals(t, 1, ran)
+				for _, r := range results {
+					testutil.Ok(t, r.err)
+				}
+			case ExclusiveQueueOne:
+				testutil.Assert(t, ran >= 2, "expected queued run, got %v runs", ran)
+				// Coalesced callers get the error of a queued run.
+				for _, r := range results {
+					if !r.res.Ran {
+						testutil.Assert(t, runErrs[r.err.Error()], "unexpected error of coalesced caller %v", r.err)
+					}
+				}
+			}
+			if tc.mode != ExclusiveSkip {
+				testutil.Assert(t, maxWaited >= 10*time.Millisecond, "expected waited time to be reported, got %v", maxWaited)
+			}
+
+			// Per-key state is cleaned up once all callers returned.
+			l.mtx.Lock()
+			testutil.Equals(t, 0, len(l.keys))
+			l.mtx.Unlock()
+		})
+	}
+}
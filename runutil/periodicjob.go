@@ -0,0 +1,139 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package runutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/efficientgo/core/errors"
+)
+
+// JobStatus is a snapshot of the PeriodicJob status.
+type JobStatus struct {
+	// Alive is true while the loop started with PeriodicJob.Repeat is running.
+	Alive bool
+	// Runs is the number of finished runs.
+	Runs int
+	// ConsecutiveFailures is the number of runs that failed since the last successful one.
+	ConsecutiveFailures int
+
+	LastRunStart time.Time
+	LastRunEnd   time.Time
+	LastSuccess  time.Time
+	// LastErr is the error returned by the last run (nil if it succeeded).
+	LastErr error
+}
+
+// PeriodicJob executes function periodically and records the status of its runs, so it can be
+// used for health and readiness probes.
+type PeriodicJob struct {
+	interval time.Duration
+	f        func() error
+	now      func() time.Time
+
+	mtx     sync.Mutex
+	created time.Time
+	running bool
+	stopped bool
+	status  JobStatus
+}
+
+// NewPeriodicJob returns PeriodicJob that executes f every interval.
+func NewPeriodicJob(interval time.Duration, f func() error) *PeriodicJob {
+	return newPeriodicJob(interval, f, time.Now)
+}
+
+func newPeriodicJob(interval time.Duration, f func() error, now func() time.Time) *PeriodicJob {
+	return &PeriodicJob{interval: interval, f: f, now: now, created: now()}
+}
+
+// Repeat executes job every interval until stopc is closed. Unlike Repeat, it does not stop when the job function returns
+// an error, so failures are only recorded in the job status (see Status and HealthCheck). It returns nil once stopc is closed.
+func (j *PeriodicJob) Repeat(stopc <-chan struct{}) error {
+	j.mtx.Lock()
+	j.status.Alive = true
+	j.stopped = false
+	j.mtx.Unlock()
+
+	defer func() {
+		j.mtx.Lock()
+		j.status.Alive = false
+		j.stopped = true
+		j.mtx.Unlock()
+	}()
+	return Repeat(j.interval, stopc, func() error {
+		// Errors are recorded by Do.
+		_ = j.Do()
+		return nil
+	})
+}
+
+// Do executes job function once and records its result. It can be used to run the job with a different
+// scheduler than Repeat (e.g. RetryWithLog).
+func (j *PeriodicJob) Do() error {
+	j.mtx.Lock()
+	j.running = true
+	j.status.LastRunStart = j.now()
+	j.mtx.Unlock()
+
+	err := j.f()
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	j.running = false
+	j.status.Runs++
+	j.status.LastRunEnd = j.now()
+	j.status.LastErr = err
+	if err != nil {
+		j.status.ConsecutiveFailures++
+		return err
+	}
+	j.status.ConsecutiveFailures = 0
+	j.status.LastSuccess = j.status.LastRunEnd
+	return nil
+}
+
+// Status returns the current status of the job.
+func (j *PeriodicJob) Status() JobStatus {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	return j.status
+}
+
+// HealthCheck returns function that returns error if the job is unhealthy, which is when:
+//   - the loop started with Repeat exited,
+//   - the job failed maxConsecutiveFailures times in a row (zero disables this check),
+//   - no run was started (or the current one did not finish) for stallFactor times the interval (zero disables this check).
+func (j *PeriodicJob) HealthCheck(maxConsecutiveFailures int, stallFactor float64) func() error {
+	return func() error {
+		j.mtx.Lock()
+		defer j.mtx.Unlock()
+
+		if j.stopped {
+			if j.status.LastErr != nil {
+				return errors.Wrap(j.status.LastErr, "periodic job exited")
+			}
+			return errors.New("periodic job exited")
+		}
+		if maxConsecutiveFailures > 0 && j.status.ConsecutiveFailures >= maxConsecutiveFailures {
+			return errors.Wrapf(j.status.LastErr, "periodic job failed %d times in a row", j.status.ConsecutiveFailures)
+		}
+		if stallFactor > 0 {
+			last := j.created
+			if !j.status.LastRunStart.IsZero() {
+				last = j.status.LastRunStart
+			}
+			if !j.running && j.status.LastRunEnd.After(last) {
+				last = j.status.LastRunEnd
+			}
+			if maxWait := time.Duration(stallFactor * float64(j.interval)); j.now().Sub(last) > maxWait {
+				return errors.Newf("periodic job stalled: no progress for %v, expected run every %v", j.now().Sub(last), j.interval)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package runutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestPeriodicJob_Status(t *testing.T) {
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+
+	var fail bool
+	j := newPeriodicJob(time.Minute, func() error {
+		now = now.Add(time.Second)
+		if fail {
+			return errors.New("sync failed")
+		}
+		return nil
+	}, clock)
+	healthy := j.HealthCheck(2, 3)
+
+	testutil.Equals(t, JobStatus{}, j.Status())
+	testutil.Ok(t, healthy())
+
+	testutil.Ok(t, j.Do())
+	testutil.Equals(t, JobStatus{
+		Runs:         1,
+		LastRunStart: time.Unix(0, 0),
+		LastRunEnd:   time.Unix(1, 0),
+		LastSuccess:  time.Unix(1, 0),
+	}, j.Status())
+	testutil.Ok(t, healthy())
+
+	fail = true
+	testutil.NotOk(t, j.Do())
+	testutil.Ok(t, healthy())
+	testutil.NotOk(t, j.Do())
+
+	st := j.Status()
+	testutil.Equals(t, 3, st.Runs)
+	testutil.Equals(t, 2, st.ConsecutiveFailures)
+	testutil.Equals(t, "sync failed", st.LastErr.Error())
+	testutil.Equals(t, time.Unix(1, 0), st.LastSuccess)
+	testutil.Equals(t, "periodic job failed 2 times in a row: sync failed", healthy().Error())
+
+	fail = false
+	testutil.Ok(t, j.Do())
+	testutil.Equals(t, 0, j.Status().ConsecutiveFailures)
+	testutil.Ok(t, healthy())
+
+	// No progress for more than 3 intervals.
+	now = now.Add(3*time.Minute + time.Second)
+	testutil.NotOk(t, healthy())
+}
+
+func TestPeriodicJob_Repeat(t *testing.T) {
+	j := NewPeriodicJob(time.Millisecond, func() error { return errors.New("fatal") })
+	alive := j.HealthCheck(0, 0)
+	healthy := j.HealthCheck(3, 0)
+	testutil.Ok(t, healthy())
+
+	stopc := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- j.Repeat(stopc) }()
+
+	// Failures do not stop the loop.
+	for j.Status().Runs < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	st := j.Status()
+	testutil.Assert(t, st.Alive)
+	testutil.Assert(t, st.ConsecutiveFailures >= 3, "expected at least 3 failures in a row, got %v", st.ConsecutiveFailures)
+	testutil.Ok(t, alive())
+	testutil.NotOk(t, healthy())
+
+	close(stopc)
+	testutil.Ok(t, <-done)
+	testutil.Equals(t, false, j.Status().Alive)
+	testutil.Equals(t, "periodic job exited: fatal", alive().Error())
+
+	// Restarted job is not reported as exited.
+	stopc = make(chan struct{})
+	go func() { done <- j.Repeat(stopc) }()
+	for !j.Status().Alive {
+		time.Sleep(time.Millisecond)
+	}
+	testutil.Ok(t, alive())
+
+	close(stopc)
+	testutil.Ok(t, <-done)
+}
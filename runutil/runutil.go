@@ -9,7 +9,12 @@
 package runutil
 
 import (
+	"context"
 	"time"
+
+	"github.com/efficientgo/core/backoff"
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
 )
 
 // Repeat executes f every interval seconds until stopc is closed or f returns an error.
@@ -30,6 +35,82 @@ func Repeat(interval time.Duration, stopc <-chan struct{}, f func() error) error
 	}
 }
 
+// RepeatWithLog executes f every interval seconds until stopc is closed. Unlike Repeat, it does not stop when f returns
+// an error. Instead, it logs the error and waits with exponential backoff (from interval up to 10 times interval)
+// while f keeps failing. It executes f once right after being called. Use RepeatWithConfig to configure the backoff.
+//
+// It returns distinct (by message) errors returned by f as merrors.Error, or nil if f never failed. It returns an
+// error without calling f if interval is not positive.
+func RepeatWithLog(logger Logger, interval time.Duration, stopc <-chan struct{}, f func() error) error {
+	return RepeatWithConfig(RepeatConfig{Interval: interval, Logger: logger}, stopc, f)
+}
+
+// RepeatConfig configures RepeatWithConfig.
+type RepeatConfig struct {
+	// Interval is the time between f calls while f succeeds. It has to be positive.
+	Interval time.Duration
+	// Backoff configures waiting after consecutive f errors. Zero Backoff.Min means Interval and zero Backoff.Max
+	// means 10 times Backoff.Min. If Backoff.MaxRetries is not zero, repeating stops after f failed
+	// Backoff.MaxRetries+1 times in a row.
+	Backoff backoff.Config
+	// Logger is used to log an error on each f error, if not nil.
+	Logger Logger
+}
+
+// RepeatWithConfig is like RepeatWithLog, but waits according to cfg.Backoff after consecutive f errors.
+// It returns an error without calling f if cfg.Interval is not positive or cfg.Backoff.Min is negative.
+func RepeatWithConfig(cfg RepeatConfig, stopc <-chan struct{}, f func() error) error {
+	if cfg.Interval <= 0 {
+		return errors.Newf("repeat interval has to be positive, got %v", cfg.Interval)
+	}
+	if cfg.Backoff.Min < 0 {
+		return errors.Newf("repeat backoff min period cannot be negative, got %v", cfg.Backoff.Min)
+	}
+	if cfg.Backoff.Min == 0 {
+		cfg.Backoff.Min = cfg.Interval
+	}
+	if cfg.Backoff.Max == 0 {
+		cfg.Backoff.Max = 10 * cfg.Backoff.Min
+	}
+
+	b := backoff.New(context.Background(), cfg.Backoff)
+	errs := newDistinctErrors()
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		wait := cfg.Interval
+		if err := f(); err != nil {
+			errs.Add(err)
+			if !b.Ongoing() {
+				return errs.Err()
+			}
+			wait = b.NextDelay()
+			if cfg.Logger != nil {
+				_ = cfg.Logger.Log("msg", "function failed. Repeating after backoff", "backoff", wait, "err", err)
+			}
+		} else {
+			b.Reset()
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+		select {
+		case <-stopc:
+			return errs.Err()
+		case <-timer.C:
+		}
+	}
+}
+
 // Logger interface compatible with go-kit/logger.
 type Logger interface {
 	Log(keyvals ...interface{}) error
@@ -60,3 +141,115 @@ func RetryWithLog(logger Logger, interval time.Duration, stopc <-chan struct{},
 		}
 	}
 }
+
+// RetryConfig configures RetryWithConfig.
+type RetryConfig struct {
+	// Interval is the time between the start of consecutive attempts. Zero means retrying immediately, so without
+	// MaxAttempts or Timeout it is a busy loop until f succeeds or stopc is closed.
+	Interval time.Duration
+	// MaxAttempts is the maximum number of f calls. Zero means no limit.
+	MaxAttempts int
+	// AttemptTimeout cancels context passed to f after given duration. Zero means no timeout.
+	AttemptTimeout time.Duration
+	// Timeout cancels context passed to f and stops retrying after given duration from the start. Zero means no timeout.
+	Timeout time.Duration
+	// Logger is used to log an error on each f error, if not nil.
+	Logger Logger
+}
+
+// RetryWithConfig executes f every cfg.Interval until no error is returned from f, stopc is closed,
+// cfg.MaxAttempts is reached or cfg.Timeout passes. The context passed to f is cancelled on any of those
+// or after cfg.AttemptTimeout, so f has to respect it to not block the retries.
+//
+// On failure, it returns the distinct (by message) errors returned by f across all attempts as merrors.Error.
+func RetryWithConfig(cfg RetryConfig, stopc <-chan struct{}, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+	}
+	defer cancel()
+
+	go func() {
+		select {
+		case <-stopc:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Closed channel makes the next attempt start immediately.
+	next := make(chan time.Time)
+	close(next)
+	var tick <-chan time.Time = next
+	if cfg.Interval > 0 {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	errs := newDistinctErrors()
+	for attempt := 1; ; attempt++ {
+		err := callWithTimeout(ctx, cfg.AttemptTimeout, f)
+		if err == nil {
+			return nil
+		}
+		errs.Add(err)
+
+		if cfg.MaxAttempts > 0 && attempt >= cfg.MaxAttempts {
+			return errs.Err()
+		}
+		if cfg.Logger != nil {
+			_ = cfg.Logger.Log("msg", "function failed. Retrying in next tick", "attempt", attempt, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return errs.Err()
+		case <-tick:
+		}
+		// Select picks randomly if several cases are ready (e.g. with zero interval), so check whether to stop again.
+		// Stopc is checked directly too, as it cancels the context asynchronously.
+		select {
+		case <-ctx.Done():
+			return errs.Err()
+		case <-stopc:
+			return errs.Err()
+		default:
+		}
+	}
+}
+
+func callWithTimeout(ctx context.Context, timeout time.Duration, f func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return f(ctx)
+}
+
+// distinctErrors collects errors, skipping those with the same message as an already collected one.
+type distinctErrors struct {
+	seen map[string]struct{}
+	errs *merrors.NilOrMultiError
+}
+
+func newDistinctErrors() *distinctErrors {
+	return &distinctErrors{seen: map[string]struct{}{}, errs: merrors.New()}
+}
+
+// Add adds error if not nil and not seen before.
+func (d *distinctErrors) Add(err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := d.seen[err.Error()]; ok {
+		return
+	}
+	d.seen[err.Error()] = struct{}{}
+	d.errs.Add(err)
+}
+
+// Err returns collected errors as merrors.Error or nil if none were added.
+func (d *distinctErrors) Err() error {
+	return d.errs.Err()
+}
@@ -0,0 +1,193 @@
+// Copyright (c) The EfficientGo Authors.
+// Licensed under the Apache License 2.0.
+
+package runutil_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/core/backoff"
+	"github.com/efficientgo/core/errors"
+	"github.com/efficientgo/core/merrors"
+	"github.com/efficientgo/core/runutil"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestRetryWithConfig(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	t.Run("success after failures", func(t *testing.T) {
+		calls := 0
+		testutil.Ok(t, runutil.RetryWithConfig(runutil.RetryConfig{Interval: time.Millisecond}, nil, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errA
+			}
+			return nil
+		}))
+		testutil.Equals(t, 3, calls)
+	})
+	t.Run("max attempts with distinct errors", func(t *testing.T) {
+		calls := 0
+		err := runutil.RetryWithConfig(runutil.RetryConfig{Interval: time.Millisecond, MaxAttempts: 4}, nil, func(context.Context) error {
+			calls++
+			if calls%2 == 0 {
+				return errB
+			}
+			return errA
+		})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, 4, calls)
+		testutil.Equals(t, "2 errors: a; b", err.Error())
+
+		merr, ok := merrors.AsMulti(err)
+		testutil.Assert(t, ok)
+		testutil.Equals(t, []error{errA, errB}, merr.Errors())
+	})
+	t.Run("attempt timeout cancels hung attempt", func(t *testing.T) {
+		calls := 0
+		err := runutil.RetryWithConfig(runutil.RetryConfig{
+			Interval:       time.Millisecond,
+			MaxAttempts:    2,
+			AttemptTimeout: 10 * time.Millisecond,
+		}, nil, func(ctx context.Context) error {
+			calls++
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		testutil.Equals(t, 2, calls)
+		testutil.Assert(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded, got %v", err)
+	})
+	t.Run("total timeout", func(t *testing.T) {
+		err := runutil.RetryWithConfig(runutil.RetryConfig{
+			Interval: time.Millisecond,
+			Timeout:  50 * time.Millisecond,
+		}, nil, func(context.Context) error {
+			return errA
+		})
+		testutil.Equals(t, errA, err.(merrors.Error).Errors()[0])
+	})
+	t.Run("zero interval retries immediately", func(t *testing.T) {
+		calls := 0
+		err := runutil.RetryWithConfig(runutil.RetryConfig{MaxAttempts: 3}, nil, func(context.Context) error {
+			calls++
+			return errA
+		})
+		testutil.Equals(t, 3, calls)
+		testutil.Equals(t, "a", err.Error())
+	})
+	t.Run("stopped", func(t *testing.T) {
+		stopc := make(chan struct{})
+		close(stopc)
+
+		calls := 0
+		err := runutil.RetryWithConfig(runutil.RetryConfig{Interval: time.Hour}, stopc, func(ctx context.Context) error {
+			calls++
+			return errA
+		})
+		testutil.Equals(t, 1, calls)
+		testutil.Equals(t, "a", err.Error())
+
+		// Not retried, even if the next attempt is ready immediately.
+		calls = 0
+		err = runutil.RetryWithConfig(runutil.RetryConfig{}, stopc, func(ctx context.Context) error {
+			calls++
+			return errA
+		})
+		testutil.Equals(t, 1, calls)
+		testutil.Equals(t, "a", err.Error())
+	})
+}
+
+type countingLogger struct{ calls int }
+
+func (l *countingLogger) Log(...interface{}) error {
+	l.calls++
+	return nil
+}
+
+func TestRepeatWithLog(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	stopc := make(chan struct{})
+	logger := &countingLogger{}
+	calls := 0
+	err := runutil.RepeatWithLog(logger, time.Millisecond, stopc, func() error {
+		calls++
+		switch {
+		case calls <= 2:
+			return errA
+		case calls == 3:
+			return errB
+		case calls == 6:
+			close(stopc)
+		}
+		return nil
+	})
+	testutil.Equals(t, 6, calls)
+	testutil.Equals(t, 3, logger.calls)
+	testutil.Equals(t, "2 errors: a; b", err.Error())
+
+	// No failures.
+	stopc = make(chan struct{})
+	close(stopc)
+	testutil.Ok(t, runutil.RepeatWithLog(nil, time.Millisecond, stopc, func() error { return nil }))
+}
+
+func TestRepeatWithLog_NonPositiveInterval(t *testing.T) {
+	calls := 0
+	err := runutil.RepeatWithLog(nil, 0, nil, func() error {
+		calls++
+		return nil
+	})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 0, calls)
+}
+
+func TestRepeatWithConfig(t *testing.T) {
+	t.Run("max retries", func(t *testing.T) {
+		calls := 0
+		err := runutil.RepeatWithConfig(runutil.RepeatConfig{
+			Interval: time.Hour,
+			Backoff:  backoff.Config{Min: time.Millisecond, Max: 2 * time.Millisecond, MaxRetries: 2},
+		}, nil, func() error {
+			calls++
+			return errors.New("a")
+		})
+		testutil.Equals(t, 3, calls)
+		testutil.Equals(t, "a", err.Error())
+	})
+	t.Run("backoff is reset on success", func(t *testing.T) {
+		stopc := make(chan struct{})
+		calls := 0
+		err := runutil.RepeatWithConfig(runutil.RepeatConfig{
+			Interval: time.Millisecond,
+			Backoff:  backoff.Config{Min: time.Millisecond, MaxRetries: 1},
+		}, stopc, func() error {
+			calls++
+			if calls == 6 {
+				close(stopc)
+			}
+			if calls%2 == 1 {
+				return errors.New("a")
+			}
+			return nil
+		})
+		testutil.Equals(t, 6, calls)
+		testutil.Equals(t, "a", err.Error())
+	})
+	t.Run("negative backoff", func(t *testing.T) {
+		calls := 0
+		err := runutil.RepeatWithConfig(runutil.RepeatConfig{
+			Interval: time.Millisecond,
+			Backoff:  backoff.Config{Min: -time.Millisecond},
+		}, nil, func() error {
+			calls++
+			return nil
+		})
+		testutil.NotOk(t, err)
+		testutil.Equals(t, 0, calls)
+	})
+}